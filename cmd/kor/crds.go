@@ -28,5 +28,6 @@ var crdCmd = &cobra.Command{
 }
 
 func init() {
+	crdCmd.Flags().BoolVar(&filterOptions.CrdCheckConversionWebhook, "check-conversion-webhook", false, "Also report CRDs whose conversion webhook Service no longer exists")
 	rootCmd.AddCommand(crdCmd)
 }
@@ -0,0 +1,34 @@
+package kor
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yonahd/kor/pkg/filters"
+	"github.com/yonahd/kor/pkg/kor"
+	"github.com/yonahd/kor/pkg/utils"
+)
+
+var cronJobCmd = &cobra.Command{
+	Use:     "cronjob",
+	Aliases: []string{"cj", "cronjobs"},
+	Short:   "Gets unused cronjobs",
+	Args:    cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, args []string) {
+		clientset := kor.GetKubeClient(kubeconfig)
+
+		if response, err := kor.GetUnusedCronJobs(filterOptions, clientset, outputFormat, opts); err != nil {
+			fmt.Println(err)
+		} else {
+			utils.PrintLogo(outputFormat)
+			fmt.Println(response)
+		}
+	},
+}
+
+func init() {
+	cronJobCmd.Flags().DurationVar(&filterOptions.CronJobStaleAfter, "stale-after", filters.DefaultCronJobStaleAfter, "How long a CronJob may miss its schedule or go without a successful run before it is considered stale. Example: --stale-after=168h")
+	cronJobCmd.Flags().IntVar(&filterOptions.CronJobFailedJobs, "failed-jobs", filters.DefaultCronJobFailedJobs, "Number of most recent Jobs that must all have failed for a CronJob to be considered broken, must be at least 1")
+	rootCmd.AddCommand(cronJobCmd)
+}
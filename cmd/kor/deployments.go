@@ -5,6 +5,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/yonahd/kor/pkg/filters"
 	"github.com/yonahd/kor/pkg/kor"
 	"github.com/yonahd/kor/pkg/utils"
 )
@@ -26,5 +27,7 @@ var deployCmd = &cobra.Command{
 }
 
 func init() {
+	deployCmd.Flags().BoolVar(&filterOptions.IncludeBroken, "include-broken", false, "Also report workloads that are paused, past their progress deadline, unavailable or unable to pull their image")
+	deployCmd.Flags().DurationVar(&filterOptions.BrokenAfter, "broken-after", filters.DefaultBrokenAfter, "How long a rollout must be stuck or unavailable before the workload is considered broken. Example: --broken-after=72h")
 	rootCmd.AddCommand(deployCmd)
 }
@@ -24,5 +24,6 @@ var exporterCmd = &cobra.Command{
 
 func init() {
 	exporterCmd.Flags().StringSliceVarP(&resourceList, "resources", "r", nil, "Comma-separated list of resources to monitor (e.g., deployment,service)")
+	exporterCmd.Flags().DurationVar(&filterOptions.CertExpiryWindow, "cert-expiry-window", 0, "Export TLS Secrets whose certificate expires within this duration. Example: --cert-expiry-window=720h")
 	rootCmd.AddCommand(exporterCmd)
 }
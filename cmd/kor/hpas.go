@@ -5,6 +5,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/yonahd/kor/pkg/filters"
 	"github.com/yonahd/kor/pkg/kor"
 	"github.com/yonahd/kor/pkg/utils"
 )
@@ -16,8 +17,9 @@ var hpaCmd = &cobra.Command{
 	Args:    cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		clientset := kor.GetKubeClient(kubeconfig)
+		dynamicClient := kor.GetDynamicClient(kubeconfig)
 
-		if response, err := kor.GetUnusedHpas(filterOptions, clientset, outputFormat, opts); err != nil {
+		if response, err := kor.GetUnusedHpas(filterOptions, clientset, dynamicClient, outputFormat, opts); err != nil {
 			fmt.Println(err)
 		} else {
 			utils.PrintLogo(outputFormat)
@@ -28,5 +30,6 @@ var hpaCmd = &cobra.Command{
 }
 
 func init() {
+	hpaCmd.Flags().DurationVar(&filterOptions.HpaFailingAfter, "failing-after", filters.DefaultHpaFailingAfter, "How long an HPA may fail to compute its metrics before it is reported. Example: --failing-after=6h")
 	rootCmd.AddCommand(hpaCmd)
 }
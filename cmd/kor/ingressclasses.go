@@ -0,0 +1,32 @@
+package kor
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yonahd/kor/pkg/kor"
+	"github.com/yonahd/kor/pkg/utils"
+)
+
+var icCmd = &cobra.Command{
+	Use:     "ingressclass",
+	Aliases: []string{"ingressclasses"},
+	Short:   "Gets unused ingressClasses",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		clientset := kor.GetKubeClient(kubeconfig)
+
+		if response, err := kor.GetUnusedIngressClasses(filterOptions, clientset, outputFormat, opts); err != nil {
+			fmt.Println(err)
+		} else {
+			utils.PrintLogo(outputFormat)
+			fmt.Println(response)
+		}
+
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(icCmd)
+}
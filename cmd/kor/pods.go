@@ -5,6 +5,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/yonahd/kor/pkg/filters"
 	"github.com/yonahd/kor/pkg/kor"
 	"github.com/yonahd/kor/pkg/utils"
 )
@@ -27,5 +28,7 @@ var podCmd = &cobra.Command{
 }
 
 func init() {
+	podCmd.Flags().DurationVar(&filterOptions.PodStuckAfter, "stuck-after", filters.DefaultPodStuckAfter, "How long a pod may stay Pending or Unknown before it is reported. Example: --stuck-after=6h")
+	podCmd.Flags().BoolVar(&filterOptions.PodAggregateEvicted, "aggregate-evicted", false, "Report evicted pods once per owning workload, cannot be used with --delete")
 	rootCmd.AddCommand(podCmd)
 }
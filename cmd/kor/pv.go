@@ -28,5 +28,6 @@ var pvCmd = &cobra.Command{
 }
 
 func init() {
+	pvCmd.Flags().DurationVar(&filterOptions.PvReleasedOlderThan, "released-older-than", 0, "Only report Released PVs with Retain policy that were released longer ago than this duration. Example: --released-older-than=720h")
 	rootCmd.AddCommand(pvCmd)
 }
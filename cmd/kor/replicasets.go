@@ -16,8 +16,9 @@ var replicaSetCmd = &cobra.Command{
 	Args:    cobra.ExactArgs(0),
 	Run: func(cmd *cobra.Command, args []string) {
 		clientset := kor.GetKubeClient(kubeconfig)
+		dynamicClient := kor.GetDynamicClient(kubeconfig)
 
-		if response, err := kor.GetUnusedReplicaSets(filterOptions, clientset, outputFormat, opts); err != nil {
+		if response, err := kor.GetUnusedReplicaSets(filterOptions, clientset, dynamicClient, outputFormat, opts); err != nil {
 			fmt.Println(err)
 		} else {
 			utils.PrintLogo(outputFormat)
@@ -27,5 +28,6 @@ var replicaSetCmd = &cobra.Command{
 }
 
 func init() {
+	replicaSetCmd.Flags().BoolVar(&filterOptions.ReplicaSetIncludeHistory, "include-history", false, "Also report old ReplicaSets a Deployment keeps for rollback under its revisionHistoryLimit")
 	rootCmd.AddCommand(replicaSetCmd)
 }
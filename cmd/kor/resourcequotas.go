@@ -0,0 +1,32 @@
+package kor
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yonahd/kor/pkg/filters"
+	"github.com/yonahd/kor/pkg/kor"
+	"github.com/yonahd/kor/pkg/utils"
+)
+
+var resourceQuotaCmd = &cobra.Command{
+	Use:     "resourcequota",
+	Aliases: []string{"quota", "resourcequotas"},
+	Short:   "Gets unused resourceQuotas",
+	Args:    cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, args []string) {
+		clientset := kor.GetKubeClient(kubeconfig)
+		if response, err := kor.GetUnusedResourceQuotas(filterOptions, clientset, outputFormat, opts); err != nil {
+			fmt.Println(err)
+		} else {
+			utils.PrintLogo(outputFormat)
+			fmt.Println(response)
+		}
+	},
+}
+
+func init() {
+	resourceQuotaCmd.Flags().DurationVar(&filterOptions.ResourceQuotaIdleAfter, "idle-after", filters.DefaultResourceQuotaIdleAfter, "How long every resource tracked by a ResourceQuota must have been unused before it is reported. Example: --idle-after=168h")
+	rootCmd.AddCommand(resourceQuotaCmd)
+}
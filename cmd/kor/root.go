@@ -29,6 +29,15 @@ var rootCmd = &cobra.Command{
 	Long: `kor is a CLI to to discover unused Kubernetes resources
 	kor can currently discover unused configmaps and secrets`,
 	Args: cobra.MinimumNArgs(1),
+	// Runs after cobra has parsed the flags of the executed subcommand, so their values are validated as well
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := filterOptions.Validate(); err != nil {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("invalid filter options: %w", err)
+		}
+		filterOptions.Modify()
+		return nil
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		resourceNames := args[0]
 		clientset := kor.GetKubeClient(kubeconfig)
@@ -66,12 +75,6 @@ func init() {
 }
 
 func Execute() {
-	_ = rootCmd.ParseFlags(os.Args)
-	if err := filterOptions.Validate(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error while validating filter options '%s'", err)
-		os.Exit(1)
-	}
-	filterOptions.Modify()
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error while executing your CLI '%s'", err)
 		os.Exit(1)
@@ -0,0 +1,57 @@
+package kor
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestSubcommandFlagsAreValidated(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"cronjob", "--failed-jobs=0"}, "CronJobFailedJobs"},
+		{[]string{"cronjob", "--failed-jobs=-1"}, "CronJobFailedJobs"},
+		{[]string{"cronjob", "--stale-after=-1h"}, "CronJobStaleAfter"},
+		{[]string{"pv", "--released-older-than=-1h"}, "PvReleasedOlderThan"},
+		{[]string{"secret", "--helm-history-limit=-1"}, "HelmHistoryLimit"},
+		{[]string{"secret", "--cert-expiry-window=-1h"}, "CertExpiryWindow"},
+		{[]string{"deployment", "--broken-after=-1h"}, "BrokenAfter"},
+		{[]string{"pod", "--stuck-after=-1h"}, "PodStuckAfter"},
+		{[]string{"hpa", "--failing-after=-1h"}, "HpaFailingAfter"},
+		{[]string{"volumesnapshot", "--snapshot-older-than=-1h"}, "VolumeSnapshotOlderThan"},
+		{[]string{"resourcequota", "--idle-after=-1h"}, "ResourceQuotaIdleAfter"},
+	}
+
+	rootCmd.SetOut(io.Discard)
+	rootCmd.SetErr(io.Discard)
+
+	for _, test := range tests {
+		t.Run(strings.Join(test.args, " "), func(t *testing.T) {
+			cmd, _, err := rootCmd.Find(test.args)
+			if err != nil {
+				t.Fatalf("Error finding command for %v: %v", test.args, err)
+			}
+			// Flag values outlive a single execution, restore them for the next case
+			t.Cleanup(func() {
+				for _, arg := range test.args[1:] {
+					name, _, _ := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+					if f := cmd.Flags().Lookup(name); f != nil {
+						_ = f.Value.Set(f.DefValue)
+						f.Changed = false
+					}
+				}
+			})
+
+			rootCmd.SetArgs(test.args)
+			err = rootCmd.Execute()
+			if err == nil {
+				t.Fatalf("Expected a validation error for %v", test.args)
+			}
+			if !strings.Contains(err.Error(), test.expected) {
+				t.Errorf("Expected error about %s, got %v", test.expected, err)
+			}
+		})
+	}
+}
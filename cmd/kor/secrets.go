@@ -27,5 +27,7 @@ var secretCmd = &cobra.Command{
 }
 
 func init() {
+	secretCmd.Flags().IntVar(&filterOptions.HelmHistoryLimit, "helm-history-limit", 0, "Number of Helm release revisions to keep, older revisions are reported as unused. 0 disables the check")
+	secretCmd.Flags().DurationVar(&filterOptions.CertExpiryWindow, "cert-expiry-window", 0, "Report TLS Secrets whose certificate expires within this duration. Example: --cert-expiry-window=720h")
 	rootCmd.AddCommand(secretCmd)
 }
@@ -0,0 +1,35 @@
+package kor
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yonahd/kor/pkg/filters"
+	"github.com/yonahd/kor/pkg/kor"
+	"github.com/yonahd/kor/pkg/utils"
+)
+
+var volumeSnapshotCmd = &cobra.Command{
+	Use:     "volumesnapshot",
+	Aliases: []string{"vs", "volumesnapshots"},
+	Short:   "Gets unused volumeSnapshots, volumeSnapshotContents and volumeSnapshotClasses",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		clientset := kor.GetKubeClient(kubeconfig)
+		dynamicClient := kor.GetDynamicClient(kubeconfig)
+
+		if response, err := kor.GetUnusedVolumeSnapshots(filterOptions, clientset, dynamicClient, outputFormat, opts); err != nil {
+			fmt.Println(err)
+		} else {
+			utils.PrintLogo(outputFormat)
+			fmt.Println(response)
+		}
+
+	},
+}
+
+func init() {
+	volumeSnapshotCmd.Flags().DurationVar(&filterOptions.VolumeSnapshotOlderThan, "snapshot-older-than", filters.DefaultVolumeSnapshotOlderThan, "Only report VolumeSnapshots whose source PVC is gone when they are older than this duration. Example: --snapshot-older-than=720h")
+	rootCmd.AddCommand(volumeSnapshotCmd)
+}
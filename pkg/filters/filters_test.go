@@ -177,3 +177,15 @@ func TestKorLabelFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateCronJobFailedJobs(t *testing.T) {
+	opts := NewFilterOptions()
+	if err := opts.Validate(); err != nil {
+		t.Fatalf("Expected default options to be valid, got %v", err)
+	}
+
+	opts.CronJobFailedJobs = 0
+	if err := opts.Validate(); err == nil {
+		t.Errorf("Expected CronJobFailedJobs 0 to be rejected")
+	}
+}
@@ -12,6 +12,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/strings/slices"
 )
 
 // Options represents the flags and options for filtering unused Kubernetes resources, such as pods, services, or configmaps.
@@ -38,16 +39,68 @@ type Options struct {
 	ExcludeNamespaces []string
 	// IncludeNamespaces is a namespace selector to include resources in matching namespaces
 	IncludeNamespaces []string
+	// CronJobStaleAfter is how long a CronJob may miss its schedule or go without a successful run before it is considered stale
+	CronJobStaleAfter time.Duration
+	// CronJobFailedJobs is the number of most recent Jobs that must all have failed for a CronJob to be considered broken
+	CronJobFailedJobs int
+	// PvReleasedOlderThan is the minimum time a Released PV with Retain policy must have been released to be reported
+	PvReleasedOlderThan time.Duration
+	// CrdCheckConversionWebhook reports CRDs whose conversion webhook Service no longer exists
+	CrdCheckConversionWebhook bool
+	// HelmHistoryLimit is the number of Helm release revisions to keep before older ones are reported, 0 disables the check
+	HelmHistoryLimit int
+	// CertExpiryWindow reports TLS Secrets whose certificate expires within this duration, 0 only reports expired certificates
+	CertExpiryWindow time.Duration
+	// IncludeBroken also reports Deployments and StatefulSets that want replicas but are paused, stuck or cannot run
+	IncludeBroken bool
+	// BrokenAfter is how long a workload rollout must be stuck or unavailable to be considered broken
+	BrokenAfter time.Duration
+	// ReplicaSetIncludeHistory also reports the old ReplicaSets a Deployment keeps for rollback
+	ReplicaSetIncludeHistory bool
+	// PodStuckAfter is how long a pod may stay Pending or Unknown before it is considered stuck
+	PodStuckAfter time.Duration
+	// PodAggregateEvicted reports evicted pods once per owning workload instead of one by one
+	PodAggregateEvicted bool
+	// HpaFailingAfter is how long an HPA may fail to compute its metrics before it is reported
+	HpaFailingAfter time.Duration
+	// VolumeSnapshotOlderThan is the minimum age of a VolumeSnapshot whose source PVC is gone before it is reported
+	VolumeSnapshotOlderThan time.Duration
+	// ResourceQuotaIdleAfter is how long every resource tracked by a ResourceQuota must have been unused before it is reported
+	ResourceQuotaIdleAfter time.Duration
 
 	namespace []string
 	once      sync.Once
 }
 
+const (
+	// DefaultCronJobStaleAfter is used when CronJobStaleAfter is not set
+	DefaultCronJobStaleAfter = 30 * 24 * time.Hour
+	// DefaultCronJobFailedJobs is used when CronJobFailedJobs is not set
+	DefaultCronJobFailedJobs = 3
+	// DefaultBrokenAfter is used when BrokenAfter is not set
+	DefaultBrokenAfter = 7 * 24 * time.Hour
+	// DefaultPodStuckAfter is used when PodStuckAfter is not set
+	DefaultPodStuckAfter = 24 * time.Hour
+	// DefaultHpaFailingAfter is used when HpaFailingAfter is not set
+	DefaultHpaFailingAfter = 24 * time.Hour
+	// DefaultVolumeSnapshotOlderThan is used when VolumeSnapshotOlderThan is not set
+	DefaultVolumeSnapshotOlderThan = 7 * 24 * time.Hour
+	// DefaultResourceQuotaIdleAfter is used when ResourceQuotaIdleAfter is not set
+	DefaultResourceQuotaIdleAfter = 30 * 24 * time.Hour
+)
+
 // NewFilterOptions returns a new FilterOptions instance with default values
 func NewFilterOptions() *Options {
 	return &Options{
-		OlderThan: "",
-		NewerThan: "",
+		OlderThan:               "",
+		NewerThan:               "",
+		CronJobStaleAfter:       DefaultCronJobStaleAfter,
+		CronJobFailedJobs:       DefaultCronJobFailedJobs,
+		BrokenAfter:             DefaultBrokenAfter,
+		PodStuckAfter:           DefaultPodStuckAfter,
+		HpaFailingAfter:         DefaultHpaFailingAfter,
+		VolumeSnapshotOlderThan: DefaultVolumeSnapshotOlderThan,
+		ResourceQuotaIdleAfter:  DefaultResourceQuotaIdleAfter,
 	}
 }
 
@@ -99,6 +152,46 @@ func (o *Options) Validate() error {
 		}
 	}
 
+	if o.CronJobStaleAfter < 0 {
+		return errors.New("CronJobStaleAfter must be a non-negative duration")
+	}
+
+	if o.CronJobFailedJobs < 1 {
+		return errors.New("CronJobFailedJobs must be a positive number")
+	}
+
+	if o.PvReleasedOlderThan < 0 {
+		return errors.New("PvReleasedOlderThan must be a non-negative duration")
+	}
+
+	if o.HelmHistoryLimit < 0 {
+		return errors.New("HelmHistoryLimit must be a non-negative number")
+	}
+
+	if o.HpaFailingAfter < 0 {
+		return errors.New("HpaFailingAfter must be a non-negative duration")
+	}
+
+	if o.VolumeSnapshotOlderThan < 0 {
+		return errors.New("VolumeSnapshotOlderThan must be a non-negative duration")
+	}
+
+	if o.ResourceQuotaIdleAfter < 0 {
+		return errors.New("ResourceQuotaIdleAfter must be a non-negative duration")
+	}
+
+	if o.PodStuckAfter < 0 {
+		return errors.New("PodStuckAfter must be a non-negative duration")
+	}
+
+	if o.BrokenAfter < 0 {
+		return errors.New("BrokenAfter must be a non-negative duration")
+	}
+
+	if o.CertExpiryWindow < 0 {
+		return errors.New("CertExpiryWindow must be a non-negative duration")
+	}
+
 	return nil
 }
 
@@ -160,6 +253,15 @@ func (o *Options) Namespaces(clientset kubernetes.Interface) []string {
 	return o.namespace
 }
 
+// IsNamespaceSelected reports whether the namespace passes the include and exclude namespace flags,
+// without checking that it exists in the cluster
+func (o *Options) IsNamespaceSelected(namespace string) bool {
+	if len(o.IncludeNamespaces) > 0 {
+		return slices.Contains(o.IncludeNamespaces, namespace)
+	}
+	return !slices.Contains(o.ExcludeNamespaces, namespace)
+}
+
 func (o *Options) modifyLabels() {
 	if o.IncludeLabels != "" {
 		if len(o.ExcludeLabels) > 0 {
@@ -120,8 +120,20 @@ func getUnusedClusterRoles(clientset kubernetes.Interface, filterOpts *filters.O
 	return aDiff
 }
 
-func getUnusedHpas(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ResourceDiff {
-	hpaDiff, err := processNamespaceHpas(clientset, namespace, filterOpts)
+func getUnusedClusterRoleBindings(clientset kubernetes.Interface, filterOpts *filters.Options) ResourceDiff {
+	clusterRoleBindingDiff, err := processClusterRoleBindings(clientset, filterOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to get %s: %v\n", "clusterRoleBindings", err)
+	}
+	aDiff := ResourceDiff{
+		"ClusterRoleBinding",
+		clusterRoleBindingDiff,
+	}
+	return aDiff
+}
+
+func getUnusedHpas(clientset kubernetes.Interface, dynamicClient dynamic.Interface, namespace string, filterOpts *filters.Options) ResourceDiff {
+	hpaDiff, _, err := processNamespaceHpas(clientset, dynamicClient, namespace, filterOpts)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to get %s namespace %s: %v\n", "hpas", namespace, err)
 	}
@@ -144,6 +156,18 @@ func getUnusedPvcs(clientset kubernetes.Interface, namespace string, filterOpts
 	return namespacePvcDiff
 }
 
+func getUnusedStatefulSetPvcs(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ResourceDiff {
+	stsPvcDiff, _, err := processNamespaceStatefulSetPvcs(clientset, namespace, filterOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to get %s namespace %s: %v\n", "statefulset pvcs", namespace, err)
+	}
+	namespaceStsPvcDiff := ResourceDiff{
+		"StatefulSetPvc",
+		stsPvcDiff,
+	}
+	return namespaceStsPvcDiff
+}
+
 func getUnusedIngresses(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ResourceDiff {
 	ingressDiff, err := processNamespaceIngresses(clientset, namespace, filterOpts)
 	if err != nil {
@@ -193,7 +217,7 @@ func getUnusedPvs(clientset kubernetes.Interface, filterOpts *filters.Options) R
 }
 
 func getUnusedPods(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ResourceDiff {
-	podDiff, err := processNamespacePods(clientset, namespace, filterOpts)
+	podDiff, _, err := processNamespacePods(clientset, namespace, retrieveNodeNames(clientset), filterOpts)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to get %s namespace %s: %v\n", "pods", namespace, err)
 	}
@@ -216,8 +240,20 @@ func getUnusedJobs(clientset kubernetes.Interface, namespace string, filterOpts
 	return namespaceJobDiff
 }
 
-func getUnusedReplicaSets(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ResourceDiff {
-	replicaSetDiff, err := processNamespaceReplicaSets(clientset, namespace, filterOpts)
+func getUnusedCronJobs(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ResourceDiff {
+	cronJobDiff, err := processNamespaceCronJobs(clientset, namespace, filterOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to get %s namespace %s: %v\n", "cronjobs", namespace, err)
+	}
+	namespaceCronJobDiff := ResourceDiff{
+		"CronJob",
+		cronJobDiff,
+	}
+	return namespaceCronJobDiff
+}
+
+func getUnusedReplicaSets(clientset kubernetes.Interface, dynamicClient dynamic.Interface, namespace string, filterOpts *filters.Options) ResourceDiff {
+	replicaSetDiff, err := processNamespaceReplicaSets(clientset, dynamicClient, namespace, filterOpts)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to get %s namespace %s: %v\n", "ReplicaSets", namespace, err)
 	}
@@ -252,6 +288,95 @@ func getUnusedStorageClasses(clientset kubernetes.Interface, filterOpts *filters
 	return allScDiff
 }
 
+func getUnusedPriorityClasses(clientset kubernetes.Interface, filterOpts *filters.Options) ResourceDiff {
+	pcDiff, err := processPriorityClasses(clientset, filterOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to get %s: %v\n", "PriorityClasses", err)
+	}
+	allPcDiff := ResourceDiff{
+		"PriorityClass",
+		pcDiff,
+	}
+	return allPcDiff
+}
+
+func getUnusedIngressClasses(clientset kubernetes.Interface, filterOpts *filters.Options) ResourceDiff {
+	icDiff, err := processIngressClasses(clientset, filterOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to get %s: %v\n", "IngressClasses", err)
+	}
+	allIcDiff := ResourceDiff{
+		"IngressClass",
+		icDiff,
+	}
+	return allIcDiff
+}
+
+func getUnusedRuntimeClasses(clientset kubernetes.Interface, filterOpts *filters.Options) ResourceDiff {
+	rcDiff, err := processRuntimeClasses(clientset, filterOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to get %s: %v\n", "RuntimeClasses", err)
+	}
+	allRcDiff := ResourceDiff{
+		"RuntimeClass",
+		rcDiff,
+	}
+	return allRcDiff
+}
+
+func getUnusedVolumeSnapshots(clientset kubernetes.Interface, dynamicClient dynamic.Interface, namespace string, filterOpts *filters.Options) ResourceDiff {
+	// Served is reported once by getUnusedVolumeSnapshotClusterResources
+	if served, err := isVolumeSnapshotServed(clientset); err != nil || !served {
+		return ResourceDiff{"VolumeSnapshot", nil}
+	}
+	vsDiff, err := processNamespaceVolumeSnapshots(clientset, dynamicClient, namespace, filterOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to get %s namespace %s: %v\n", "VolumeSnapshots", namespace, err)
+	}
+	namespaceVsDiff := ResourceDiff{
+		"VolumeSnapshot",
+		vsDiff,
+	}
+	return namespaceVsDiff
+}
+
+func getUnusedVolumeSnapshotClusterResources(clientset kubernetes.Interface, dynamicClient dynamic.Interface, filterOpts *filters.Options) []ResourceDiff {
+	served, err := isVolumeSnapshotServed(clientset)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to discover %s: %v\n", volumeSnapshotGVR.GroupVersion(), err)
+		return nil
+	}
+	if !served {
+		fmt.Fprintf(os.Stderr, "%s is not served, skipping VolumeSnapshots\n", volumeSnapshotGVR.GroupVersion())
+		return nil
+	}
+
+	vscDiff, err := processVolumeSnapshotContents(dynamicClient, filterOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to get %s: %v\n", "VolumeSnapshotContents", err)
+	}
+	vsClassDiff, err := processVolumeSnapshotClasses(dynamicClient, filterOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to get %s: %v\n", "VolumeSnapshotClasses", err)
+	}
+	return []ResourceDiff{
+		{"VolumeSnapshotContent", vscDiff},
+		{"VolumeSnapshotClass", vsClassDiff},
+	}
+}
+
+func getUnusedNamespaces(clientset kubernetes.Interface, dynamicClient dynamic.Interface, filterOpts *filters.Options) ResourceDiff {
+	namespaceDiff, err := processNamespaces(clientset, dynamicClient, filterOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to get %s: %v\n", "Namespaces", err)
+	}
+	allNamespaceDiff := ResourceDiff{
+		"Namespace",
+		namespaceDiff,
+	}
+	return allNamespaceDiff
+}
+
 func getUnusedNetworkPolicies(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ResourceDiff {
 	netpolDiff, err := processNamespaceNetworkPolicies(clientset, namespace, filterOpts)
 	if err != nil {
@@ -277,7 +402,31 @@ func getUnusedRoleBindings(clientset kubernetes.Interface, namespace string, fil
 	return namespaceRoleBindingDiff
 }
 
-func GetUnusedAllNamespaced(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+func getUnusedResourceQuotas(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ResourceDiff {
+	resourceQuotaDiff, err := processNamespaceResourceQuotas(clientset, namespace, filterOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to get %s namespace %s: %v\n", "ResourceQuotas", namespace, err)
+	}
+	namespaceResourceQuotaDiff := ResourceDiff{
+		"ResourceQuota",
+		resourceQuotaDiff,
+	}
+	return namespaceResourceQuotaDiff
+}
+
+func getUnusedLimitRanges(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ResourceDiff {
+	limitRangeDiff, err := processNamespaceLimitRanges(clientset, namespace, filterOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to get %s namespace %s: %v\n", "LimitRanges", namespace, err)
+	}
+	namespaceLimitRangeDiff := ResourceDiff{
+		"LimitRange",
+		limitRangeDiff,
+	}
+	return namespaceLimitRangeDiff
+}
+
+func GetUnusedAllNamespaced(filterOpts *filters.Options, clientset kubernetes.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts) (string, error) {
 	resources := make(map[string]map[string][]ResourceInfo)
 	for _, namespace := range filterOpts.Namespaces(clientset) {
 		switch opts.GroupBy {
@@ -290,16 +439,20 @@ func GetUnusedAllNamespaced(filterOpts *filters.Options, clientset kubernetes.In
 			resources[namespace]["Deployment"] = getUnusedDeployments(clientset, namespace, filterOpts).diff
 			resources[namespace]["StatefulSet"] = getUnusedStatefulSets(clientset, namespace, filterOpts).diff
 			resources[namespace]["Role"] = getUnusedRoles(clientset, namespace, filterOpts).diff
-			resources[namespace]["Hpa"] = getUnusedHpas(clientset, namespace, filterOpts).diff
+			resources[namespace]["Hpa"] = getUnusedHpas(clientset, dynamicClient, namespace, filterOpts).diff
 			resources[namespace]["Pvc"] = getUnusedPvcs(clientset, namespace, filterOpts).diff
+			resources[namespace]["StatefulSetPvc"] = getUnusedStatefulSetPvcs(clientset, namespace, filterOpts).diff
 			resources[namespace]["Pod"] = getUnusedPods(clientset, namespace, filterOpts).diff
 			resources[namespace]["Ingress"] = getUnusedIngresses(clientset, namespace, filterOpts).diff
 			resources[namespace]["Pdb"] = getUnusedPdbs(clientset, namespace, filterOpts).diff
 			resources[namespace]["Job"] = getUnusedJobs(clientset, namespace, filterOpts).diff
-			resources[namespace]["ReplicaSet"] = getUnusedReplicaSets(clientset, namespace, filterOpts).diff
+			resources[namespace]["CronJob"] = getUnusedCronJobs(clientset, namespace, filterOpts).diff
+			resources[namespace]["ReplicaSet"] = getUnusedReplicaSets(clientset, dynamicClient, namespace, filterOpts).diff
 			resources[namespace]["DaemonSet"] = getUnusedDaemonSets(clientset, namespace, filterOpts).diff
 			resources[namespace]["NetworkPolicy"] = getUnusedNetworkPolicies(clientset, namespace, filterOpts).diff
 			resources[namespace]["RoleBinding"] = getUnusedRoleBindings(clientset, namespace, filterOpts).diff
+			resources[namespace]["ResourceQuota"] = getUnusedResourceQuotas(clientset, namespace, filterOpts).diff
+			resources[namespace]["LimitRange"] = getUnusedLimitRanges(clientset, namespace, filterOpts).diff
 		case "resource":
 			appendResources(resources, "ConfigMap", namespace, getUnusedCMs(clientset, namespace, filterOpts).diff)
 			appendResources(resources, "Service", namespace, getUnusedSVCs(clientset, namespace, filterOpts).diff)
@@ -308,16 +461,20 @@ func GetUnusedAllNamespaced(filterOpts *filters.Options, clientset kubernetes.In
 			appendResources(resources, "Deployment", namespace, getUnusedDeployments(clientset, namespace, filterOpts).diff)
 			appendResources(resources, "StatefulSet", namespace, getUnusedStatefulSets(clientset, namespace, filterOpts).diff)
 			appendResources(resources, "Role", namespace, getUnusedRoles(clientset, namespace, filterOpts).diff)
-			appendResources(resources, "Hpa", namespace, getUnusedHpas(clientset, namespace, filterOpts).diff)
+			appendResources(resources, "Hpa", namespace, getUnusedHpas(clientset, dynamicClient, namespace, filterOpts).diff)
 			appendResources(resources, "Pvc", namespace, getUnusedPvcs(clientset, namespace, filterOpts).diff)
+			appendResources(resources, "StatefulSetPvc", namespace, getUnusedStatefulSetPvcs(clientset, namespace, filterOpts).diff)
 			appendResources(resources, "Pod", namespace, getUnusedPods(clientset, namespace, filterOpts).diff)
 			appendResources(resources, "Ingress", namespace, getUnusedIngresses(clientset, namespace, filterOpts).diff)
 			appendResources(resources, "Pdb", namespace, getUnusedPdbs(clientset, namespace, filterOpts).diff)
 			appendResources(resources, "Job", namespace, getUnusedJobs(clientset, namespace, filterOpts).diff)
-			appendResources(resources, "ReplicaSet", namespace, getUnusedReplicaSets(clientset, namespace, filterOpts).diff)
+			appendResources(resources, "CronJob", namespace, getUnusedCronJobs(clientset, namespace, filterOpts).diff)
+			appendResources(resources, "ReplicaSet", namespace, getUnusedReplicaSets(clientset, dynamicClient, namespace, filterOpts).diff)
 			appendResources(resources, "DaemonSet", namespace, getUnusedDaemonSets(clientset, namespace, filterOpts).diff)
 			appendResources(resources, "NetworkPolicy", namespace, getUnusedNetworkPolicies(clientset, namespace, filterOpts).diff)
 			appendResources(resources, "RoleBinding", namespace, getUnusedRoleBindings(clientset, namespace, filterOpts).diff)
+			appendResources(resources, "ResourceQuota", namespace, getUnusedResourceQuotas(clientset, namespace, filterOpts).diff)
+			appendResources(resources, "LimitRange", namespace, getUnusedLimitRanges(clientset, namespace, filterOpts).diff)
 		}
 	}
 
@@ -349,12 +506,20 @@ func GetUnusedAllNonNamespaced(filterOpts *filters.Options, clientset kubernetes
 		resources[""]["Crd"] = getUnusedCrds(apiExtClient, dynamicClient, filterOpts).diff
 		resources[""]["Pv"] = getUnusedPvs(clientset, filterOpts).diff
 		resources[""]["ClusterRole"] = getUnusedClusterRoles(clientset, filterOpts).diff
+		resources[""]["ClusterRoleBinding"] = getUnusedClusterRoleBindings(clientset, filterOpts).diff
 		resources[""]["StorageClass"] = getUnusedStorageClasses(clientset, filterOpts).diff
+		resources[""]["PriorityClass"] = getUnusedPriorityClasses(clientset, filterOpts).diff
+		resources[""]["IngressClass"] = getUnusedIngressClasses(clientset, filterOpts).diff
+		resources[""]["RuntimeClass"] = getUnusedRuntimeClasses(clientset, filterOpts).diff
 	case "resource":
 		appendResources(resources, "Crd", "", getUnusedCrds(apiExtClient, dynamicClient, filterOpts).diff)
 		appendResources(resources, "Pv", "", getUnusedPvs(clientset, filterOpts).diff)
 		appendResources(resources, "ClusterRole", "", getUnusedClusterRoles(clientset, filterOpts).diff)
+		appendResources(resources, "ClusterRoleBinding", "", getUnusedClusterRoleBindings(clientset, filterOpts).diff)
 		appendResources(resources, "StorageClass", "", getUnusedStorageClasses(clientset, filterOpts).diff)
+		appendResources(resources, "PriorityClass", "", getUnusedPriorityClasses(clientset, filterOpts).diff)
+		appendResources(resources, "IngressClass", "", getUnusedIngressClasses(clientset, filterOpts).diff)
+		appendResources(resources, "RuntimeClass", "", getUnusedRuntimeClasses(clientset, filterOpts).diff)
 	}
 
 	var outputBuffer bytes.Buffer
@@ -378,7 +543,7 @@ func GetUnusedAllNonNamespaced(filterOpts *filters.Options, clientset kubernetes
 }
 
 func GetUnusedAll(filterOpts *filters.Options, clientset kubernetes.Interface, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts) (string, error) {
-	unusedAllNamespaced, err := GetUnusedAllNamespaced(filterOpts, clientset, outputFormat, opts)
+	unusedAllNamespaced, err := GetUnusedAllNamespaced(filterOpts, clientset, dynamicClient, outputFormat, opts)
 	if err != nil {
 		fmt.Printf("err: %v\n", err)
 	}
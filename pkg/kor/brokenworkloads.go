@@ -0,0 +1,142 @@
+package kor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+var imagePullFailureReasons = []string{"ErrImagePull", "ImagePullBackOff", "InvalidImageName"}
+
+// Desired replicas of a workload, the API server defaults a nil value to 1
+func desiredReplicas(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+func retrieveBrokenWorkloadPods(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]corev1.Pod, error) {
+	if !filterOpts.IncludeBroken {
+		return nil, nil
+	}
+	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return pods.Items, nil
+}
+
+func selectWorkloadPods(selector *metav1.LabelSelector, pods []corev1.Pod) []corev1.Pod {
+	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil || labelSelector.Empty() {
+		return nil
+	}
+	var selected []corev1.Pod
+	for _, pod := range pods {
+		if labelSelector.Matches(labels.Set(pod.Labels)) {
+			selected = append(selected, pod)
+		}
+	}
+	return selected
+}
+
+// Return the image and reason when every pod fails to pull one of its images
+func imagePullFailure(pods []corev1.Pod) (string, string) {
+	if len(pods) == 0 {
+		return "", ""
+	}
+	var image, reason string
+	for _, pod := range pods {
+		failing := false
+		statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+		for _, status := range statuses {
+			if status.State.Waiting != nil && contains(imagePullFailureReasons, status.State.Waiting.Reason) {
+				failing = true
+				image, reason = status.Image, status.State.Waiting.Reason
+				break
+			}
+		}
+		if !failing {
+			return "", ""
+		}
+	}
+	return image, reason
+}
+
+// Estimate since when none of the pods has been ready, from their latest Ready transition
+func unavailableSince(pods []corev1.Pod, fallback time.Time) time.Time {
+	since := fallback
+	for _, pod := range pods {
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodReady && condition.LastTransitionTime.Time.After(since) {
+				since = condition.LastTransitionTime.Time
+			}
+		}
+	}
+	return since
+}
+
+func deploymentCondition(deployment appsv1.Deployment, conditionType appsv1.DeploymentConditionType) *appsv1.DeploymentCondition {
+	for i := range deployment.Status.Conditions {
+		if deployment.Status.Conditions[i].Type == conditionType {
+			return &deployment.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// Classify a Deployment that still wants replicas but is not running, or return an empty string
+func brokenDeploymentReason(deployment appsv1.Deployment, pods []corev1.Pod, brokenAfter time.Duration, now time.Time) string {
+	if deployment.Spec.Paused {
+		return "Deployment rollout is paused"
+	}
+
+	if progressing := deploymentCondition(deployment, appsv1.DeploymentProgressing); progressing != nil &&
+		progressing.Status == corev1.ConditionFalse && progressing.Reason == "ProgressDeadlineExceeded" &&
+		now.Sub(progressing.LastUpdateTime.Time) >= brokenAfter {
+		return fmt.Sprintf("Deployment rollout has exceeded its progress deadline since %s", progressing.LastUpdateTime.UTC().Format(time.RFC3339))
+	}
+
+	workloadPods := selectWorkloadPods(deployment.Spec.Selector, pods)
+	if image, reason := imagePullFailure(workloadPods); image != "" {
+		return fmt.Sprintf("Deployment image %s cannot be pulled on any pod (%s)", image, reason)
+	}
+
+	if deployment.Status.AvailableReplicas == 0 {
+		since := unavailableSince(workloadPods, deployment.CreationTimestamp.Time)
+		if available := deploymentCondition(deployment, appsv1.DeploymentAvailable); available != nil && available.Status == corev1.ConditionFalse {
+			since = available.LastTransitionTime.Time
+		}
+		if now.Sub(since) >= brokenAfter {
+			return fmt.Sprintf("Deployment has had no available replicas since %s", since.UTC().Format(time.RFC3339))
+		}
+	}
+
+	return ""
+}
+
+// Classify a StatefulSet that still wants replicas but is not running, or return an empty string
+func brokenStatefulSetReason(statefulSet appsv1.StatefulSet, pods []corev1.Pod, brokenAfter time.Duration, now time.Time) string {
+	workloadPods := selectWorkloadPods(statefulSet.Spec.Selector, pods)
+	if image, reason := imagePullFailure(workloadPods); image != "" {
+		return fmt.Sprintf("StatefulSet image %s cannot be pulled on any pod (%s)", image, reason)
+	}
+
+	if statefulSet.Status.AvailableReplicas == 0 {
+		since := unavailableSince(workloadPods, statefulSet.CreationTimestamp.Time)
+		if now.Sub(since) >= brokenAfter {
+			return fmt.Sprintf("StatefulSet has had no available replicas since %s", since.UTC().Format(time.RFC3339))
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,154 @@
+package kor
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	v1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+//go:embed exceptions/clusterrolebindings/clusterrolebindings.json
+var clusterRoleBindingsConfig []byte
+
+// Explain why none of the ServiceAccount subjects of a ClusterRoleBinding exist, or return an empty string
+func invalidServiceAccountSubjectsReason(serviceAccounts []v1.Subject, namespaceNames map[string]bool, serviceAccountNames map[string]bool) string {
+	missingNamespaces := 0
+	for _, sa := range serviceAccounts {
+		if !namespaceNames[sa.Namespace] {
+			missingNamespaces++
+			continue
+		}
+		if serviceAccountNames[sa.Namespace+"/"+sa.Name] {
+			return ""
+		}
+	}
+
+	if missingNamespaces == len(serviceAccounts) {
+		return "ClusterRoleBinding references ServiceAccounts in non-existing namespaces"
+	}
+	return "ClusterRoleBinding references non-existing ServiceAccounts"
+}
+
+func processClusterRoleBindings(clientset kubernetes.Interface, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	clusterRoleBindings, err := clientset.RbacV1().ClusterRoleBindings().List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+	if err != nil {
+		return nil, err
+	}
+
+	clusterRoles, err := clientset.RbacV1().ClusterRoles().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	clusterRoleNames := make(map[string]bool, len(clusterRoles.Items))
+	for _, clusterRole := range clusterRoles.Items {
+		clusterRoleNames[clusterRole.Name] = true
+	}
+
+	namespaces, err := clientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	namespaceNames := make(map[string]bool, len(namespaces.Items))
+	for _, namespace := range namespaces.Items {
+		namespaceNames[namespace.Name] = true
+	}
+
+	serviceAccounts, err := clientset.CoreV1().ServiceAccounts(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	serviceAccountNames := make(map[string]bool, len(serviceAccounts.Items))
+	for _, sa := range serviceAccounts.Items {
+		serviceAccountNames[sa.Namespace+"/"+sa.Name] = true
+	}
+
+	config, err := unmarshalConfig(clusterRoleBindingsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var unusedClusterRoleBindings []ResourceInfo
+
+	for _, crb := range clusterRoleBindings.Items {
+		if pass, _ := filter.SetObject(&crb).Run(filterOpts); pass {
+			continue
+		}
+
+		if crb.Labels["kor/used"] == "false" {
+			unusedClusterRoleBindings = append(unusedClusterRoleBindings, ResourceInfo{Name: crb.Name, Reason: "Marked with unused label"})
+			continue
+		}
+
+		if exceptionFound, err := isResourceException(crb.Name, crb.Namespace, config.ExceptionClusterRoleBindings); err != nil {
+			return nil, err
+		} else if exceptionFound {
+			continue
+		}
+
+		if crb.RoleRef.Kind == "ClusterRole" && !clusterRoleNames[crb.RoleRef.Name] {
+			unusedClusterRoleBindings = append(unusedClusterRoleBindings, ResourceInfo{Name: crb.Name, Reason: "ClusterRoleBinding references a non-existing ClusterRole"})
+			continue
+		}
+
+		serviceAccountSubjects := filterSubjects(crb.Subjects, "ServiceAccount")
+
+		// If other kinds (Users/Groups) are used, we assume they exists for now
+		if len(serviceAccountSubjects) == 0 || len(serviceAccountSubjects) != len(crb.Subjects) {
+			continue
+		}
+
+		if reason := invalidServiceAccountSubjectsReason(serviceAccountSubjects, namespaceNames, serviceAccountNames); reason != "" {
+			unusedClusterRoleBindings = append(unusedClusterRoleBindings, ResourceInfo{Name: crb.Name, Reason: reason})
+		}
+	}
+
+	return unusedClusterRoleBindings, nil
+}
+
+func GetUnusedClusterRoleBindings(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	resources := make(map[string]map[string][]ResourceInfo)
+	diff, err := processClusterRoleBindings(clientset, filterOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to process cluster role bindings: %v\n", err)
+	}
+	if opts.DeleteFlag {
+		if diff, err = DeleteResource(diff, clientset, "", "ClusterRoleBinding", opts.NoInteractive); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to delete ClusterRoleBinding %s: %v\n", diff, err)
+		}
+	}
+	switch opts.GroupBy {
+	case "namespace":
+		resources[""] = make(map[string][]ResourceInfo)
+		resources[""]["ClusterRoleBinding"] = diff
+	case "resource":
+		appendResources(resources, "ClusterRoleBinding", "", diff)
+	}
+
+	var outputBuffer bytes.Buffer
+	var jsonResponse []byte
+	switch outputFormat {
+	case "table":
+		outputBuffer = FormatOutput(resources, opts)
+	case "json", "yaml":
+		var err error
+		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
+			return "", err
+		}
+	}
+
+	unusedClusterRoleBindings, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
+	if err != nil {
+		fmt.Printf("err: %v\n", err)
+	}
+
+	return unusedClusterRoleBindings, nil
+}
@@ -0,0 +1,119 @@
+package kor
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func createTestClusterRoleBindings(t *testing.T) *fake.Clientset {
+	clientset := fake.NewSimpleClientset()
+
+	_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), &corev1.Namespace{
+		ObjectMeta: v1.ObjectMeta{Name: testNamespace},
+	}, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
+	}
+
+	_, err = clientset.CoreV1().ServiceAccounts(testNamespace).Create(context.TODO(), CreateTestServiceAccount(testNamespace, "existing-sa", AppLabels), v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake %s: %v", "ServiceAccount", err)
+	}
+
+	_, err = clientset.RbacV1().ClusterRoles().Create(context.TODO(), CreateTestClusterRole("existing-clusterrole", AppLabels), v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake %s: %v", "ClusterRole", err)
+	}
+
+	existingRoleRef := CreateTestRoleRefForClusterRole("existing-clusterrole")
+
+	userBinding := CreateTestClusterRoleBindingRoleRef(testNamespace, "test-crb-user", "", existingRoleRef)
+	userBinding.Subjects = []rbacv1.Subject{{Kind: "User", Name: "jane"}}
+
+	mixedBinding := CreateTestClusterRoleBindingRoleRef(testNamespace, "test-crb-mixed", "existing-sa", existingRoleRef)
+	mixedBinding.Subjects = append(mixedBinding.Subjects, *CreateTestRbacSubject("deleted-namespace", "sa"))
+
+	clusterRoleBindings := []*rbacv1.ClusterRoleBinding{
+		CreateTestClusterRoleBindingRoleRef(testNamespace, "test-crb-valid", "existing-sa", existingRoleRef),
+		CreateTestClusterRoleBindingRoleRef(testNamespace, "test-crb-missing-role", "existing-sa", CreateTestRoleRefForClusterRole("missing-clusterrole")),
+		CreateTestClusterRoleBindingRoleRef(testNamespace, "test-crb-missing-sa", "missing-sa", existingRoleRef),
+		CreateTestClusterRoleBindingRoleRef("deleted-namespace", "test-crb-missing-namespace", "sa", existingRoleRef),
+		CreateTestClusterRoleBindingRoleRef(testNamespace, "system:test-crb", "missing-sa", existingRoleRef),
+		userBinding,
+		mixedBinding,
+	}
+	for _, clusterRoleBinding := range clusterRoleBindings {
+		_, err = clientset.RbacV1().ClusterRoleBindings().Create(context.TODO(), clusterRoleBinding, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake %s: %v", "ClusterRoleBinding", err)
+		}
+	}
+
+	return clientset
+}
+
+func TestProcessClusterRoleBindings(t *testing.T) {
+	clientset := createTestClusterRoleBindings(t)
+
+	unusedClusterRoleBindings, err := processClusterRoleBindings(clientset, &filters.Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []ResourceInfo{
+		{Name: "test-crb-missing-namespace", Reason: "ClusterRoleBinding references ServiceAccounts in non-existing namespaces"},
+		{Name: "test-crb-missing-role", Reason: "ClusterRoleBinding references a non-existing ClusterRole"},
+		{Name: "test-crb-missing-sa", Reason: "ClusterRoleBinding references non-existing ServiceAccounts"},
+	}
+
+	if !reflect.DeepEqual(unusedClusterRoleBindings, expected) {
+		t.Errorf("Expected %v, got %v", expected, unusedClusterRoleBindings)
+	}
+}
+
+func TestGetUnusedClusterRoleBindingsStructured(t *testing.T) {
+	clientset := createTestClusterRoleBindings(t)
+
+	opts := common.Opts{
+		WebhookURL:    "",
+		Channel:       "",
+		Token:         "",
+		DeleteFlag:    false,
+		NoInteractive: true,
+		GroupBy:       "namespace",
+	}
+
+	output, err := GetUnusedClusterRoleBindings(&filters.Options{}, clientset, "json", opts)
+	if err != nil {
+		t.Fatalf("Error calling GetUnusedClusterRoleBindingsStructured: %v", err)
+	}
+
+	expectedOutput := map[string]map[string][]string{
+		"": {
+			"ClusterRoleBinding": {
+				"test-crb-missing-namespace",
+				"test-crb-missing-role",
+				"test-crb-missing-sa",
+			},
+		},
+	}
+
+	var actualOutput map[string]map[string][]string
+	if err := json.Unmarshal([]byte(output), &actualOutput); err != nil {
+		t.Fatalf("Error unmarshaling actual output: %v", err)
+	}
+
+	if !reflect.DeepEqual(expectedOutput, actualOutput) {
+		t.Errorf("Expected output does not match actual output")
+	}
+}
@@ -7,10 +7,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"strconv"
+	"sort"
+	"strings"
 
 	v1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
 	"k8s.io/utils/strings/slices"
@@ -22,6 +24,74 @@ import (
 //go:embed exceptions/clusterroles/clusterroles.json
 var clusterRolesConfig []byte
 
+// clusterRoleAggregation records that member is aggregated into another ClusterRole through a selector
+type clusterRoleAggregation struct {
+	member string
+	into   string
+	via    string
+}
+
+// Describe a selector by its label keys, e.g. rbac.authorization.k8s.io/aggregate-to-admin
+func describeLabelSelector(selector metav1.LabelSelector) string {
+	keys := make([]string, 0, len(selector.MatchLabels)+len(selector.MatchExpressions))
+	for key := range selector.MatchLabels {
+		keys = append(keys, key)
+	}
+	for _, expression := range selector.MatchExpressions {
+		if !slices.Contains(keys, expression.Key) {
+			keys = append(keys, expression.Key)
+		}
+	}
+	if len(keys) == 0 {
+		return "empty selector"
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ", ")
+}
+
+// Resolve the aggregationRule of every ClusterRole against the labels of the others
+func retrieveClusterRoleAggregations(clusterRoles []v1.ClusterRole) []clusterRoleAggregation {
+	var aggregations []clusterRoleAggregation
+	for _, aggregator := range clusterRoles {
+		if aggregator.AggregationRule == nil {
+			continue
+		}
+		for _, clusterRoleSelector := range aggregator.AggregationRule.ClusterRoleSelectors {
+			selector, err := metav1.LabelSelectorAsSelector(&clusterRoleSelector)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Skipping invalid aggregation selector of ClusterRole %s: %v\n", aggregator.Name, err)
+				continue
+			}
+			via := describeLabelSelector(clusterRoleSelector)
+			for _, clusterRole := range clusterRoles {
+				if clusterRole.Name == aggregator.Name || !selector.Matches(labels.Set(clusterRole.Labels)) {
+					continue
+				}
+				aggregations = append(aggregations, clusterRoleAggregation{member: clusterRole.Name, into: aggregator.Name, via: via})
+			}
+		}
+	}
+	return aggregations
+}
+
+// Explain through which ClusterRoles, themselves unused, a ClusterRole is aggregated
+func describeAggregationChain(clusterRole string, aggregations map[string][]clusterRoleAggregation, visited map[string]bool) string {
+	if visited[clusterRole] {
+		return ""
+	}
+	visited[clusterRole] = true
+
+	var links []string
+	for _, aggregation := range aggregations[clusterRole] {
+		link := fmt.Sprintf("aggregated into %s via %s", aggregation.into, aggregation.via)
+		if parent := describeAggregationChain(aggregation.into, aggregations, visited); parent != "" {
+			link = fmt.Sprintf("%s, which is %s", link, parent)
+		}
+		links = append(links, link)
+	}
+	return strings.Join(links, "; ")
+}
+
 func retrieveUsedClusterRoles(clientset kubernetes.Interface, filterOpts *filters.Options) ([]string, error) {
 
 	//Get a list of all namespaces
@@ -67,44 +137,27 @@ func retrieveUsedClusterRoles(clientset kubernetes.Interface, filterOpts *filter
 	if err != nil {
 		return nil, fmt.Errorf("failed to list cluster roles %v", err)
 	}
-	// Convert the ClusterRole list into a Map
-	clusterRolesMap := make(map[string]v1.ClusterRole)
-	for _, clusterRole := range clusterRoles.Items {
-		clusterRolesMap[clusterRole.Name] = clusterRole
-	}
-	// Create a list wich holds all aggregated labels
-	aggregatedLabels := make([]string, 0)
 
+	// ClusterRoles aggregated into a used ClusterRole are used as well, transitively
+	members := make(map[string][]string)
+	for _, aggregation := range retrieveClusterRoleAggregations(clusterRoles.Items) {
+		members[aggregation.into] = append(members[aggregation.into], aggregation.member)
+	}
+	queue := make([]string, 0, len(usedClusterRoles))
 	for clusterRole := range usedClusterRoles {
-		clusterRoleManifest := clusterRolesMap[clusterRole]
-		if clusterRolesMap[clusterRole].AggregationRule == nil {
-			continue
-		}
-		for _, label := range clusterRoleManifest.AggregationRule.ClusterRoleSelectors {
-			for key, value := range label.MatchLabels {
-				aggregatedLabels = append(aggregatedLabels, fmt.Sprintf("%s: %s", key, value))
-			}
-		}
-
-		for _, clusterRole := range clusterRoles.Items {
-			for label, value := range clusterRole.Labels {
-				if slices.Contains(aggregatedLabels, label+": "+value) {
-					usedClusterRoles[clusterRole.Name], err = strconv.ParseBool(value)
-					if err != nil {
-						return nil, fmt.Errorf("couldn't convert string to bool %v", err)
-					}
-					if clusterRole.AggregationRule == nil {
-						continue
-					}
-					for _, label := range clusterRole.AggregationRule.ClusterRoleSelectors {
-						for key, value := range label.MatchLabels {
-							aggregatedLabels = append(aggregatedLabels, key+": "+value)
-						}
-					}
-				}
+		queue = append(queue, clusterRole)
+	}
+	for len(queue) > 0 {
+		clusterRole := queue[0]
+		queue = queue[1:]
+		for _, member := range members[clusterRole] {
+			if !usedClusterRoles[member] {
+				usedClusterRoles[member] = true
+				queue = append(queue, member)
 			}
 		}
 	}
+
 	var usedClusterRoleNames []string
 	for role := range usedClusterRoles {
 		usedClusterRoleNames = append(usedClusterRoleNames, role)
@@ -164,10 +217,22 @@ func processClusterRoles(clientset kubernetes.Interface, filterOpts *filters.Opt
 		return nil, err
 	}
 
+	clusterRoles, err := clientset.RbacV1().ClusterRoles().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	aggregations := make(map[string][]clusterRoleAggregation)
+	for _, aggregation := range retrieveClusterRoleAggregations(clusterRoles.Items) {
+		aggregations[aggregation.member] = append(aggregations[aggregation.member], aggregation)
+	}
+
 	var diff []ResourceInfo
 
 	for _, name := range CalculateResourceDifference(usedClusterRoles, clusterRoleNames) {
 		reason := "ClusterRole is not used by any RoleBinding or ClusterRoleBinding"
+		if chain := describeAggregationChain(name, aggregations, map[string]bool{}); chain != "" {
+			reason = fmt.Sprintf("%s, only %s", reason, chain)
+		}
 		diff = append(diff, ResourceInfo{Name: name, Reason: reason})
 	}
 
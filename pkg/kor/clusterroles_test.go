@@ -9,6 +9,7 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/fake"
@@ -136,6 +137,63 @@ func TestProcessClusterRoles(t *testing.T) {
 	}
 }
 
+func TestClusterRoleAggregationChains(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+
+	aggregateToAdmin := v1.LabelSelector{
+		MatchExpressions: []v1.LabelSelectorRequirement{
+			{Key: "example.com/aggregate-to-admin", Operator: v1.LabelSelectorOpIn, Values: []string{"yes"}},
+		},
+	}
+	aggregateToEdit := v1.LabelSelector{MatchLabels: map[string]string{"example.com/aggregate-to-edit": "enabled"}}
+	aggregateToParent := v1.LabelSelector{MatchLabels: map[string]string{"example.com/aggregate-to-parent": "true"}}
+
+	clusterRoles := []*rbacv1.ClusterRole{
+		CreateTestClusterRole("test-admin", AppLabels, aggregateToAdmin),
+		CreateTestClusterRole("test-edit", map[string]string{"example.com/aggregate-to-admin": "yes"}, aggregateToEdit),
+		CreateTestClusterRole("test-view", map[string]string{"example.com/aggregate-to-edit": "enabled"}),
+		CreateTestClusterRole("test-parent", AppLabels, aggregateToParent),
+		CreateTestClusterRole("test-child", map[string]string{"example.com/aggregate-to-parent": "true"}),
+	}
+	for _, clusterRole := range clusterRoles {
+		_, err := clientset.RbacV1().ClusterRoles().Create(context.TODO(), clusterRole, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake %s: %v", "clusterRole", err)
+		}
+	}
+
+	testClusterRoleBinding := CreateTestClusterRoleBindingRoleRef(testNamespace, "test-crb", "test-sa", CreateTestRoleRefForClusterRole("test-admin"))
+	_, err := clientset.RbacV1().ClusterRoleBindings().Create(context.TODO(), testClusterRoleBinding, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake %s: %v", "clusterRoleBinding", err)
+	}
+
+	usedClusterRoles, err := retrieveUsedClusterRoles(clientset, &filters.Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	sort.Strings(usedClusterRoles)
+	expectedRoles := []string{"test-admin", "test-edit", "test-view"}
+	if !reflect.DeepEqual(usedClusterRoles, expectedRoles) {
+		t.Errorf("Expected %v, got %v", expectedRoles, usedClusterRoles)
+	}
+
+	unusedClusterRoles, err := processClusterRoles(clientset, &filters.Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	sort.Slice(unusedClusterRoles, func(i, j int) bool {
+		return unusedClusterRoles[i].Name < unusedClusterRoles[j].Name
+	})
+	expectedUnused := []ResourceInfo{
+		{Name: "test-child", Reason: "ClusterRole is not used by any RoleBinding or ClusterRoleBinding, only aggregated into test-parent via example.com/aggregate-to-parent"},
+		{Name: "test-parent", Reason: "ClusterRole is not used by any RoleBinding or ClusterRoleBinding"},
+	}
+	if !reflect.DeepEqual(unusedClusterRoles, expectedUnused) {
+		t.Errorf("Expected %v, got %v", expectedUnused, unusedClusterRoles)
+	}
+}
+
 func TestGetUnusedClusterRolesStructured(t *testing.T) {
 	clientset := createTestClusterRoles(t)
 
@@ -7,8 +7,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
@@ -21,6 +25,72 @@ import (
 //go:embed exceptions/crds/crds.json
 var crdsConfig []byte
 
+// Pick the version to list instances through: the storage version when served, otherwise the first served one
+func crdListVersion(crd apiextensionsv1.CustomResourceDefinition) (string, bool) {
+	for _, version := range crd.Spec.Versions {
+		if version.Storage && version.Served {
+			return version.Name, true
+		}
+	}
+	for _, version := range crd.Spec.Versions {
+		if version.Served {
+			return version.Name, true
+		}
+	}
+	return "", false
+}
+
+func isNamespaceScoping(filterOpts *filters.Options) bool {
+	return len(filterOpts.IncludeNamespaces) > 0 || len(filterOpts.ExcludeNamespaces) > 0
+}
+
+func formatNamespaceCounts(instancesPerNamespace map[string]int) string {
+	namespaces := make([]string, 0, len(instancesPerNamespace))
+	for namespace := range instancesPerNamespace {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	counts := make([]string, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		counts = append(counts, fmt.Sprintf("%s: %d", namespace, instancesPerNamespace[namespace]))
+	}
+	return strings.Join(counts, ", ")
+}
+
+// Only --include-namespaces names the selected namespaces, --exclude-namespaces leaves them open
+func formatSelectedNamespaceCounts(filterOpts *filters.Options, instancesPerNamespace map[string]int) string {
+	if len(filterOpts.IncludeNamespaces) == 0 {
+		return ""
+	}
+	selected := make(map[string]int, len(filterOpts.IncludeNamespaces))
+	for _, namespace := range filterOpts.IncludeNamespaces {
+		selected[namespace] = instancesPerNamespace[namespace]
+	}
+	return " (" + formatNamespaceCounts(selected) + ")"
+}
+
+func checkCrdConversionWebhook(crd apiextensionsv1.CustomResourceDefinition, dynamicClient dynamic.Interface) string {
+	conversion := crd.Spec.Conversion
+	if conversion == nil || conversion.Strategy != apiextensionsv1.WebhookConverter || conversion.Webhook == nil || conversion.Webhook.ClientConfig == nil {
+		return ""
+	}
+	service := conversion.Webhook.ClientConfig.Service
+	if service == nil {
+		return ""
+	}
+
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "services"}
+	_, err := dynamicClient.Resource(gvr).Namespace(service.Namespace).Get(context.TODO(), service.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return fmt.Sprintf("CRD conversion webhook Service %s/%s no longer exists", service.Namespace, service.Name)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to get conversion webhook Service %s/%s of CRD %s: %v\n", service.Namespace, service.Name, crd.Name, err)
+	}
+	return ""
+}
+
 func processCrds(apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, filterOpts *filters.Options) ([]ResourceInfo, error) {
 
 	var unusedCRDs []ResourceInfo
@@ -36,7 +106,7 @@ func processCrds(apiExtClient apiextensionsclientset.Interface, dynamicClient dy
 	}
 
 	for _, crd := range crds.Items {
-		if pass := filters.KorLabelFilter(&crd, &filters.Options{}); pass {
+		if pass, _ := filter.SetObject(&crd).Run(filterOpts); pass {
 			continue
 		}
 
@@ -49,26 +119,69 @@ func processCrds(apiExtClient apiextensionsclientset.Interface, dynamicClient dy
 			continue
 		}
 
+		if len(crd.Spec.Versions) == 0 {
+			fmt.Fprintf(os.Stderr, "Skipping CRD %s: no versions defined\n", crd.Name)
+			continue
+		}
+
+		if filterOpts.CrdCheckConversionWebhook {
+			if reason := checkCrdConversionWebhook(crd, dynamicClient); reason != "" {
+				unusedCRDs = append(unusedCRDs, ResourceInfo{Name: crd.Name, Reason: reason})
+				continue
+			}
+		}
+
+		version, served := crdListVersion(crd)
+		if !served {
+			unusedCRDs = append(unusedCRDs, ResourceInfo{Name: crd.Name, Reason: "CRD has no served versions"})
+			continue
+		}
+
 		gvr := schema.GroupVersionResource{
 			Group:    crd.Spec.Group,
-			Version:  crd.Spec.Versions[0].Name, // We're checking the first version.
+			Version:  version,
 			Resource: crd.Spec.Names.Plural,
 		}
-		instances, err := dynamicClient.Resource(gvr).Namespace("").List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+		// --include-labels selects the CRDs, their instances are counted regardless of their labels
+		instances, err := dynamicClient.Resource(gvr).Namespace("").List(context.TODO(), metav1.ListOptions{})
 		if err != nil {
-			return nil, err
+			fmt.Fprintf(os.Stderr, "Failed to list instances of CRD %s: %v\n", crd.Name, err)
+			continue
 		}
+
 		if len(instances.Items) == 0 {
 			reason := "CRD has no instances"
 			unusedCRDs = append(unusedCRDs, ResourceInfo{Name: crd.Name, Reason: reason})
+			continue
+		}
+
+		if crd.Spec.Scope != apiextensionsv1.NamespaceScoped || !isNamespaceScoping(filterOpts) {
+			continue
+		}
+
+		instancesPerNamespace := make(map[string]int)
+		for _, instance := range instances.Items {
+			instancesPerNamespace[instance.GetNamespace()]++
+		}
+
+		selectedInstances := 0
+		for namespace, count := range instancesPerNamespace {
+			if filterOpts.IsNamespaceSelected(namespace) {
+				selectedInstances += count
+			}
+		}
+		if selectedInstances == 0 {
+			reason := fmt.Sprintf("CRD has no instances in the selected namespaces%s, %d found in other namespaces (%s)",
+				formatSelectedNamespaceCounts(filterOpts, instancesPerNamespace), len(instances.Items), formatNamespaceCounts(instancesPerNamespace))
+			unusedCRDs = append(unusedCRDs, ResourceInfo{Name: crd.Name, Reason: reason})
 		}
 	}
 	return unusedCRDs, nil
 }
 
-func GetUnusedCrds(_ *filters.Options, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts) (string, error) {
+func GetUnusedCrds(filterOpts *filters.Options, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts) (string, error) {
 	resources := make(map[string]map[string][]ResourceInfo)
-	diff, err := processCrds(apiExtClient, dynamicClient, &filters.Options{})
+	diff, err := processCrds(apiExtClient, dynamicClient, filterOpts)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to process crds: %v\n", err)
 	}
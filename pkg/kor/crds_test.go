@@ -0,0 +1,118 @@
+package kor
+
+import (
+	"testing"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func createTestCrd(name, plural string, scope apiextensionsv1.ResourceScope, versions ...apiextensionsv1.CustomResourceDefinitionVersion) *apiextensionsv1.CustomResourceDefinition {
+	return &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: v1.ObjectMeta{Name: name},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group:    "testgroup",
+			Names:    apiextensionsv1.CustomResourceDefinitionNames{Plural: plural},
+			Scope:    scope,
+			Versions: versions,
+		},
+	}
+}
+
+func TestProcessCrds(t *testing.T) {
+	served := func(name string, storage bool) apiextensionsv1.CustomResourceDefinitionVersion {
+		return apiextensionsv1.CustomResourceDefinitionVersion{Name: name, Served: true, Storage: storage}
+	}
+
+	converted := createTestCrd("converted.testgroup", "converteds", apiextensionsv1.NamespaceScoped, served("v1", false), served("v2", true))
+	converted.Spec.Conversion = &apiextensionsv1.CustomResourceConversion{
+		Strategy: apiextensionsv1.WebhookConverter,
+		Webhook: &apiextensionsv1.WebhookConversion{
+			ClientConfig: &apiextensionsv1.WebhookClientConfig{
+				Service: &apiextensionsv1.ServiceReference{Namespace: testNamespace, Name: "missing-webhook"},
+			},
+		},
+	}
+
+	apiExtClient := apiextensionsfake.NewSimpleClientset(
+		createTestCrd("stored.testgroup", "storeds", apiextensionsv1.NamespaceScoped, served("v1", false), served("v2", true)),
+		createTestCrd("empty.testgroup", "empty", apiextensionsv1.ClusterScoped, served("v1", true)),
+		createTestCrd("unserved.testgroup", "unserved", apiextensionsv1.ClusterScoped, apiextensionsv1.CustomResourceDefinitionVersion{Name: "v1", Storage: true}),
+		createTestCrd("broken.testgroup", "broken", apiextensionsv1.ClusterScoped),
+		createTestCrd("elsewhere.testgroup", "elsewheres", apiextensionsv1.NamespaceScoped, served("v1", true)),
+		converted,
+	)
+
+	dynamicClient := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			{Group: "testgroup", Version: "v2", Resource: "storeds"}:    "StoredList",
+			{Group: "testgroup", Version: "v1", Resource: "empty"}:      "EmptyList",
+			{Group: "testgroup", Version: "v1", Resource: "elsewheres"}: "ElsewhereList",
+			{Group: "testgroup", Version: "v2", Resource: "converteds"}: "ConvertedList",
+			{Version: "v1", Resource: "services"}:                       "ServiceList",
+		},
+		CreateTestUnstructered("Stored", "testgroup/v2", testNamespace, "stored-instance"),
+		CreateTestUnstructered("Elsewhere", "testgroup/v1", "other-namespace", "elsewhere-instance"),
+		CreateTestUnstructered("Elsewhere", "testgroup/v1", "another-namespace", "elsewhere-instance"),
+		CreateTestUnstructered("Elsewhere", "testgroup/v1", "another-namespace", "other-elsewhere-instance"),
+		CreateTestUnstructered("Converted", "testgroup/v2", testNamespace, "converted-instance"),
+	)
+
+	filterOpts := filters.NewFilterOptions()
+	filterOpts.IncludeNamespaces = []string{testNamespace}
+	filterOpts.CrdCheckConversionWebhook = true
+
+	unusedCrds, err := processCrds(apiExtClient, dynamicClient, filterOpts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expectedReasons := map[string]string{
+		"converted.testgroup": "CRD conversion webhook Service test-namespace/missing-webhook no longer exists",
+		"elsewhere.testgroup": "CRD has no instances in the selected namespaces (test-namespace: 0), 3 found in other namespaces (another-namespace: 2, other-namespace: 1)",
+		"empty.testgroup":     "CRD has no instances",
+		"unserved.testgroup":  "CRD has no served versions",
+	}
+
+	if len(unusedCrds) != len(expectedReasons) {
+		t.Errorf("Expected %d unused CRDs, got %d: %v", len(expectedReasons), len(unusedCrds), unusedCrds)
+	}
+
+	for _, crd := range unusedCrds {
+		if expected, ok := expectedReasons[crd.Name]; !ok || crd.Reason != expected {
+			t.Errorf("Unexpected reason %q for CRD %s", crd.Reason, crd.Name)
+		}
+	}
+}
+
+func TestProcessCrdsIncludeLabels(t *testing.T) {
+	labelled := createTestCrd("labelled.testgroup", "labelleds", apiextensionsv1.NamespaceScoped,
+		apiextensionsv1.CustomResourceDefinitionVersion{Name: "v1", Served: true, Storage: true})
+	labelled.Labels = map[string]string{"team": "storage"}
+
+	apiExtClient := apiextensionsfake.NewSimpleClientset(labelled)
+	dynamicClient := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			{Group: "testgroup", Version: "v1", Resource: "labelleds"}: "LabelledList",
+		},
+		CreateTestUnstructered("Labelled", "testgroup/v1", testNamespace, "unlabelled-instance"),
+	)
+
+	// --include-labels selects the CRDs, the unlabelled instance still counts
+	filterOpts := filters.NewFilterOptions()
+	filterOpts.IncludeLabels = "team=storage"
+
+	unusedCrds, err := processCrds(apiExtClient, dynamicClient, filterOpts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(unusedCrds) != 0 {
+		t.Errorf("Expected no unused CRDs, got %v", unusedCrds)
+	}
+}
@@ -6,11 +6,15 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	nodev1 "k8s.io/api/node/v1"
 	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
 	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 var testNamespace = "test-namespace"
@@ -187,8 +191,9 @@ func CreateTestHpa(namespace, name, deploymentName string, minReplicas, maxRepli
 			MinReplicas: &minReplicas,
 			MaxReplicas: maxReplicas,
 			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
-				Kind: "Deployment",
-				Name: deploymentName,
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       deploymentName,
 			},
 		},
 	}
@@ -257,6 +262,78 @@ func CreateTestPv(name, phase string, labels map[string]string, storageClass str
 	}
 }
 
+func CreateTestPriorityClass(name string, value int32, globalDefault bool, labels map[string]string) *schedulingv1.PriorityClass {
+	return &schedulingv1.PriorityClass{
+		ObjectMeta: v1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+		Value:         value,
+		GlobalDefault: globalDefault,
+	}
+}
+
+func CreateTestResourceQuota(namespace, name string, hard, used corev1.ResourceList, labels map[string]string) *corev1.ResourceQuota {
+	return &corev1.ResourceQuota{
+		ObjectMeta: v1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+			Labels:    labels,
+		},
+		Spec: corev1.ResourceQuotaSpec{
+			Hard: hard,
+		},
+		Status: corev1.ResourceQuotaStatus{
+			Hard: hard,
+			Used: used,
+		},
+	}
+}
+
+func CreateTestLimitRange(namespace, name string, labels map[string]string) *corev1.LimitRange {
+	return &corev1.LimitRange{
+		ObjectMeta: v1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+			Labels:    labels,
+		},
+		Spec: corev1.LimitRangeSpec{
+			Limits: []corev1.LimitRangeItem{
+				{
+					Type:           corev1.LimitTypeContainer,
+					DefaultRequest: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+				},
+			},
+		},
+	}
+}
+
+func CreateTestIngressClass(name string, isDefault bool, labels map[string]string) *networkingv1.IngressClass {
+	ingressClass := &networkingv1.IngressClass{
+		ObjectMeta: v1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+		Spec: networkingv1.IngressClassSpec{
+			Controller: "k8s.io/" + name,
+		},
+	}
+	if isDefault {
+		ingressClass.Annotations = map[string]string{networkingv1.AnnotationIsDefaultIngressClass: "true"}
+	}
+	return ingressClass
+}
+
+func CreateTestRuntimeClass(name, handler string, labels map[string]string) *nodev1.RuntimeClass {
+	return &nodev1.RuntimeClass{
+		ObjectMeta: v1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+		Handler: handler,
+	}
+}
+
 func CreateTestStorageClass(name, provisioner string) *storagev1.StorageClass {
 	return &storagev1.StorageClass{
 		ObjectMeta: v1.ObjectMeta{
@@ -431,3 +508,19 @@ func CreateTestNetworkPolicy(name, namespace string, labels map[string]string, p
 		},
 	}
 }
+
+func CreateTestCronJob(namespace, name, schedule string, suspend bool, status *batchv1.CronJobStatus, labels map[string]string) *batchv1.CronJob {
+	return &batchv1.CronJob{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+			UID:       types.UID(name),
+		},
+		Spec: batchv1.CronJobSpec{
+			Schedule: schedule,
+			Suspend:  &suspend,
+		},
+		Status: *status,
+	}
+}
@@ -0,0 +1,422 @@
+package kor
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+//go:embed exceptions/cronjobs/cronjobs.json
+var cronJobsConfig []byte
+
+// cronSchedule is a parsed standard 5 field cron expression, as accepted by the CronJob controller
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar record whether the day fields were unrestricted ("*" or "?")
+	domStar, dowStar bool
+	location         *time.Location
+}
+
+type cronField struct {
+	min, max int
+	names    map[string]int
+}
+
+var (
+	cronMinutes = cronField{0, 59, nil}
+	cronHours   = cronField{0, 23, nil}
+	cronDom     = cronField{1, 31, nil}
+	cronMonths  = cronField{1, 12, map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	cronDow = cronField{0, 6, map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Maximum number of days in each month, February counted with its leap day
+var cronDaysInMonth = [13]int{0, 31, 29, 31, 30, 31, 30, 31, 31, 30, 31, 30, 31}
+
+func parseCronSchedule(spec string, timeZone *string) (*cronSchedule, error) {
+	location := time.UTC
+	if timeZone != nil && *timeZone != "" {
+		loc, err := time.LoadLocation(*timeZone)
+		if err != nil {
+			return nil, fmt.Errorf("unknown time zone %s: %v", *timeZone, err)
+		}
+		location = loc
+	}
+
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "TZ=") || strings.HasPrefix(spec, "CRON_TZ=") {
+		i := strings.Index(spec, " ")
+		if i == -1 {
+			return nil, fmt.Errorf("missing schedule after time zone in %q", spec)
+		}
+		loc, err := time.LoadLocation(spec[strings.Index(spec, "=")+1 : i])
+		if err != nil {
+			return nil, fmt.Errorf("unknown time zone in %q: %v", spec, err)
+		}
+		location = loc
+		spec = strings.TrimSpace(spec[i:])
+	}
+
+	if strings.HasPrefix(spec, "@") {
+		expanded, ok := cronDescriptors[strings.ToLower(spec)]
+		if !ok {
+			return nil, fmt.Errorf("unsupported descriptor %q", spec)
+		}
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 fields, found %d in %q", len(fields), spec)
+	}
+
+	schedule := &cronSchedule{location: location}
+	var err error
+	if schedule.minute, _, err = parseCronField(fields[0], cronMinutes); err != nil {
+		return nil, err
+	}
+	if schedule.hour, _, err = parseCronField(fields[1], cronHours); err != nil {
+		return nil, err
+	}
+	if schedule.dom, schedule.domStar, err = parseCronField(fields[2], cronDom); err != nil {
+		return nil, err
+	}
+	if schedule.month, _, err = parseCronField(fields[3], cronMonths); err != nil {
+		return nil, err
+	}
+	if schedule.dow, schedule.dowStar, err = parseCronField(fields[4], cronDow); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func parseCronField(field string, bounds cronField) (uint64, bool, error) {
+	var bits uint64
+	star := false
+	for _, expr := range strings.Split(field, ",") {
+		rangeAndStep := strings.SplitN(expr, "/", 2)
+		low, high := bounds.min, bounds.max
+		step := 1
+
+		switch rangeAndStep[0] {
+		case "*", "?":
+			star = len(rangeAndStep) == 1
+		default:
+			lowAndHigh := strings.SplitN(rangeAndStep[0], "-", 2)
+			var err error
+			if low, err = parseCronValue(lowAndHigh[0], bounds); err != nil {
+				return 0, false, err
+			}
+			high = low
+			if len(lowAndHigh) == 2 {
+				if high, err = parseCronValue(lowAndHigh[1], bounds); err != nil {
+					return 0, false, err
+				}
+			} else if len(rangeAndStep) == 2 {
+				high = bounds.max
+			}
+		}
+
+		if len(rangeAndStep) == 2 {
+			var err error
+			if step, err = strconv.Atoi(rangeAndStep[1]); err != nil || step <= 0 {
+				return 0, false, fmt.Errorf("invalid step in %q", expr)
+			}
+		}
+
+		if low > high {
+			return 0, false, fmt.Errorf("beginning of range is after end of range in %q", expr)
+		}
+
+		for i := low; i <= high; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+	return bits, star, nil
+}
+
+func parseCronValue(value string, bounds cronField) (int, error) {
+	if n, ok := bounds.names[strings.ToLower(value)]; ok {
+		return n, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", value)
+	}
+	if n < bounds.min || n > bounds.max {
+		return 0, fmt.Errorf("value %d out of range [%d, %d]", n, bounds.min, bounds.max)
+	}
+	return n, nil
+}
+
+// canFire reports whether there is any calendar day on which the schedule would run
+func (s *cronSchedule) canFire() bool {
+	if s.minute == 0 || s.hour == 0 || s.month == 0 {
+		return false
+	}
+	// When both day fields are restricted a day matches if either of them does,
+	// and every weekday occurs in every month
+	if s.domStar || !s.dowStar {
+		return true
+	}
+	for month := 1; month <= 12; month++ {
+		if s.month&(1<<uint(month)) == 0 {
+			continue
+		}
+		for day := 1; day <= cronDaysInMonth[month]; day++ {
+			if s.dom&(1<<uint(day)) != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	if s.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// next returns the first activation strictly after t, or the zero time when there is none within five years
+func (s *cronSchedule) next(t time.Time) time.Time {
+	t = t.In(s.location).Truncate(time.Minute).Add(time.Minute)
+	end := t.AddDate(5, 0, 0)
+	for t.Before(end) {
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.location)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.location)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func isJobFailed(job batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// Group the namespace Jobs by the UID of the CronJob that created them
+func retrieveCronJobHistory(clientset kubernetes.Interface, namespace string) (map[types.UID][]batchv1.Job, error) {
+	jobs, err := clientset.BatchV1().Jobs(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	history := make(map[types.UID][]batchv1.Job)
+	for _, job := range jobs.Items {
+		for _, owner := range job.OwnerReferences {
+			if owner.Kind == "CronJob" {
+				history[owner.UID] = append(history[owner.UID], job)
+			}
+		}
+	}
+
+	for uid := range history {
+		sort.Slice(history[uid], func(i, j int) bool {
+			return history[uid][j].CreationTimestamp.Before(&history[uid][i].CreationTimestamp)
+		})
+	}
+	return history, nil
+}
+
+func checkCronJobSchedule(cronJob batchv1.CronJob, staleAfter time.Duration, now time.Time) string {
+	schedule, err := parseCronSchedule(cronJob.Spec.Schedule, cronJob.Spec.TimeZone)
+	if err != nil {
+		return fmt.Sprintf("CronJob schedule %q is invalid: %v", cronJob.Spec.Schedule, err)
+	}
+
+	if !schedule.canFire() {
+		return fmt.Sprintf("CronJob schedule %q can never fire", cronJob.Spec.Schedule)
+	}
+
+	lastScheduled := cronJob.CreationTimestamp.Time
+	if cronJob.Status.LastScheduleTime != nil {
+		lastScheduled = cronJob.Status.LastScheduleTime.Time
+	}
+
+	if lastScheduled.IsZero() {
+		return ""
+	}
+
+	if due := schedule.next(lastScheduled); !due.IsZero() && now.Sub(due) > staleAfter {
+		return fmt.Sprintf("CronJob has not been scheduled since %s although it was due at %s", lastScheduled.UTC().Format(time.RFC3339), due.UTC().Format(time.RFC3339))
+	}
+
+	lastSuccessful := cronJob.Status.LastSuccessfulTime
+	if lastSuccessful != nil && cronJob.Status.LastScheduleTime != nil &&
+		lastSuccessful.Before(cronJob.Status.LastScheduleTime) && now.Sub(lastSuccessful.Time) > staleAfter {
+		return fmt.Sprintf("CronJob has not completed successfully since %s", lastSuccessful.UTC().Format(time.RFC3339))
+	}
+
+	return ""
+}
+
+func processNamespaceCronJobs(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	cronJobsList, err := clientset.BatchV1().CronJobs(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := unmarshalConfig(cronJobsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := retrieveCronJobHistory(clientset, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	staleAfter := filterOpts.CronJobStaleAfter
+	if staleAfter == 0 {
+		staleAfter = filters.DefaultCronJobStaleAfter
+	}
+	// Validate rejects values below 1 from the command line, options built in code fall back to the default
+	failedJobs := filterOpts.CronJobFailedJobs
+	if failedJobs <= 0 {
+		failedJobs = filters.DefaultCronJobFailedJobs
+	}
+
+	var unusedCronJobs []ResourceInfo
+	now := time.Now()
+
+	for _, cronJob := range cronJobsList.Items {
+		if pass, _ := filter.SetObject(&cronJob).Run(filterOpts); pass {
+			continue
+		}
+
+		exceptionFound, err := isResourceException(cronJob.Name, cronJob.Namespace, config.ExceptionCronJobs)
+		if err != nil {
+			return nil, err
+		}
+
+		if exceptionFound {
+			continue
+		}
+
+		if cronJob.Labels["kor/used"] == "false" {
+			unusedCronJobs = append(unusedCronJobs, ResourceInfo{Name: cronJob.Name, Reason: "Marked with unused label"})
+			continue
+		}
+
+		if cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend {
+			unusedCronJobs = append(unusedCronJobs, ResourceInfo{Name: cronJob.Name, Reason: "CronJob is suspended"})
+			continue
+		}
+
+		if reason := checkCronJobSchedule(cronJob, staleAfter, now); reason != "" {
+			unusedCronJobs = append(unusedCronJobs, ResourceInfo{Name: cronJob.Name, Reason: reason})
+			continue
+		}
+
+		jobs := history[cronJob.UID]
+		if len(jobs) >= failedJobs {
+			allFailed := true
+			for _, job := range jobs[:failedJobs] {
+				if !isJobFailed(job) {
+					allFailed = false
+					break
+				}
+			}
+			if allFailed {
+				reason := fmt.Sprintf("Last %d Jobs of CronJob have failed", failedJobs)
+				unusedCronJobs = append(unusedCronJobs, ResourceInfo{Name: cronJob.Name, Reason: reason})
+			}
+		}
+	}
+
+	return unusedCronJobs, nil
+}
+
+func GetUnusedCronJobs(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	resources := make(map[string]map[string][]ResourceInfo)
+	for _, namespace := range filterOpts.Namespaces(clientset) {
+		diff, err := processNamespaceCronJobs(clientset, namespace, filterOpts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
+			continue
+		}
+		if opts.DeleteFlag {
+			if diff, err = DeleteResource(diff, clientset, namespace, "CronJob", opts.NoInteractive); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to delete CronJob %s in namespace %s: %v\n", diff, namespace, err)
+			}
+		}
+		switch opts.GroupBy {
+		case "namespace":
+			resources[namespace] = make(map[string][]ResourceInfo)
+			resources[namespace]["CronJob"] = diff
+		case "resource":
+			appendResources(resources, "CronJob", namespace, diff)
+		}
+	}
+
+	var outputBuffer bytes.Buffer
+	var jsonResponse []byte
+	switch outputFormat {
+	case "table":
+		outputBuffer = FormatOutput(resources, opts)
+	case "json", "yaml":
+		var err error
+		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
+			return "", err
+		}
+	}
+
+	unusedCronJobs, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
+	if err != nil {
+		fmt.Printf("err: %v\n", err)
+	}
+
+	return unusedCronJobs, nil
+}
@@ -0,0 +1,203 @@
+package kor
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func createTestCronJobs(t *testing.T) *fake.Clientset {
+	clientset := fake.NewSimpleClientset()
+
+	_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), &corev1.Namespace{
+		ObjectMeta: v1.ObjectMeta{Name: testNamespace},
+	}, v1.CreateOptions{})
+
+	if err != nil {
+		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
+	}
+
+	recently := &v1.Time{Time: time.Now().Add(-time.Minute)}
+	longAgo := &v1.Time{Time: time.Now().Add(-90 * 24 * time.Hour)}
+
+	cronJobs := []*batchv1.CronJob{
+		// Healthy CronJob
+		CreateTestCronJob(testNamespace, "test-cronjob1", "* * * * *", false, &batchv1.CronJobStatus{
+			LastScheduleTime:   recently,
+			LastSuccessfulTime: recently,
+		}, AppLabels),
+		// Suspended CronJob
+		CreateTestCronJob(testNamespace, "test-cronjob2", "* * * * *", true, &batchv1.CronJobStatus{}, AppLabels),
+		// Schedule that can never fire
+		CreateTestCronJob(testNamespace, "test-cronjob3", "0 0 30 2 *", false, &batchv1.CronJobStatus{}, AppLabels),
+		// Schedule has been missed for a long time
+		CreateTestCronJob(testNamespace, "test-cronjob4", "0 * * * *", false, &batchv1.CronJobStatus{
+			LastScheduleTime: longAgo,
+		}, AppLabels),
+		// Last Jobs all failed
+		CreateTestCronJob(testNamespace, "test-cronjob5", "* * * * *", false, &batchv1.CronJobStatus{
+			LastScheduleTime: recently,
+		}, AppLabels),
+		// Suspended but marked as used
+		CreateTestCronJob(testNamespace, "test-cronjob6", "* * * * *", true, &batchv1.CronJobStatus{}, UsedLabels),
+		// Healthy but marked as unused
+		CreateTestCronJob(testNamespace, "test-cronjob7", "* * * * *", false, &batchv1.CronJobStatus{
+			LastScheduleTime: recently,
+		}, UnusedLabels),
+	}
+
+	for _, cronJob := range cronJobs {
+		_, err = clientset.BatchV1().CronJobs(testNamespace).Create(context.TODO(), cronJob, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake cronjob: %v", err)
+		}
+	}
+
+	for i, name := range []string{"test-cronjob5-1", "test-cronjob5-2", "test-cronjob5-3"} {
+		job := CreateTestJob(testNamespace, name, &batchv1.JobStatus{
+			Failed: 1,
+			Conditions: []batchv1.JobCondition{
+				{
+					Type:   batchv1.JobFailed,
+					Status: corev1.ConditionTrue,
+					Reason: "BackoffLimitExceeded",
+				},
+			},
+		}, AppLabels)
+		job.CreationTimestamp = v1.Time{Time: time.Now().Add(-time.Duration(i) * time.Minute)}
+		job.OwnerReferences = []v1.OwnerReference{
+			{Kind: "CronJob", Name: "test-cronjob5", UID: "test-cronjob5"},
+		}
+
+		_, err = clientset.BatchV1().Jobs(testNamespace).Create(context.TODO(), job, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake job: %v", err)
+		}
+	}
+
+	return clientset
+}
+
+func TestProcessNamespaceCronJobs(t *testing.T) {
+	clientset := createTestCronJobs(t)
+
+	unusedCronJobs, err := processNamespaceCronJobs(clientset, testNamespace, filters.NewFilterOptions())
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	expectedCronJobs := []ResourceInfo{
+		{Name: "test-cronjob2", Reason: "CronJob is suspended"},
+		{Name: "test-cronjob3", Reason: `CronJob schedule "0 0 30 2 *" can never fire`},
+		{Name: "test-cronjob4"},
+		{Name: "test-cronjob5", Reason: "Last 3 Jobs of CronJob have failed"},
+		{Name: "test-cronjob7", Reason: "Marked with unused label"},
+	}
+
+	if len(unusedCronJobs) != len(expectedCronJobs) {
+		t.Fatalf("Expected %d cronjobs unused got %d", len(expectedCronJobs), len(unusedCronJobs))
+	}
+
+	for i, cronJob := range unusedCronJobs {
+		if cronJob.Name != expectedCronJobs[i].Name {
+			t.Errorf("Expected %s, got %s", expectedCronJobs[i].Name, cronJob.Name)
+		}
+		if expectedCronJobs[i].Reason != "" && cronJob.Reason != expectedCronJobs[i].Reason {
+			t.Errorf("Expected reason %q for %s, got %q", expectedCronJobs[i].Reason, cronJob.Name, cronJob.Reason)
+		}
+	}
+}
+
+func TestParseCronSchedule(t *testing.T) {
+	tests := []struct {
+		spec     string
+		valid    bool
+		canFire  bool
+		timeZone *string
+	}{
+		{spec: "*/5 * * * *", valid: true, canFire: true},
+		{spec: "@daily", valid: true, canFire: true},
+		{spec: "CRON_TZ=Europe/Berlin 0 9 * * mon-fri", valid: true, canFire: true},
+		{spec: "0 0 29 2 *", valid: true, canFire: true},
+		{spec: "0 0 31 apr,jun *", valid: true, canFire: false},
+		{spec: "0 0 30 2 1", valid: true, canFire: true},
+		{spec: "0 0 * * *", valid: true, canFire: true, timeZone: func() *string { tz := "America/New_York"; return &tz }()},
+		{spec: "60 * * * *", valid: false},
+		{spec: "* * *", valid: false},
+		{spec: "@fortnightly", valid: false},
+		{spec: "5-1 * * * *", valid: false},
+	}
+
+	for _, test := range tests {
+		schedule, err := parseCronSchedule(test.spec, test.timeZone)
+		if test.valid != (err == nil) {
+			t.Errorf("Expected valid=%v for %q, got error %v", test.valid, test.spec, err)
+			continue
+		}
+		if err == nil && schedule.canFire() != test.canFire {
+			t.Errorf("Expected canFire=%v for %q", test.canFire, test.spec)
+		}
+	}
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	schedule, err := parseCronSchedule("30 2 * * sun", nil)
+	if err != nil {
+		t.Fatalf("Error parsing schedule: %v", err)
+	}
+
+	from := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	expected := time.Date(2024, time.March, 3, 2, 30, 0, 0, time.UTC)
+	if next := schedule.next(from); !next.Equal(expected) {
+		t.Errorf("Expected next activation %s, got %s", expected, next)
+	}
+}
+
+func TestGetUnusedCronJobsStructured(t *testing.T) {
+	clientset := createTestCronJobs(t)
+
+	opts := common.Opts{
+		WebhookURL:    "",
+		Channel:       "",
+		Token:         "",
+		DeleteFlag:    false,
+		NoInteractive: true,
+		GroupBy:       "namespace",
+	}
+
+	output, err := GetUnusedCronJobs(filters.NewFilterOptions(), clientset, "json", opts)
+	if err != nil {
+		t.Fatalf("Error calling GetUnusedCronJobsStructured: %v", err)
+	}
+
+	expectedOutput := map[string]map[string][]string{
+		testNamespace: {
+			"CronJob": {
+				"test-cronjob2",
+				"test-cronjob3",
+				"test-cronjob4",
+				"test-cronjob5",
+				"test-cronjob7",
+			},
+		},
+	}
+
+	var actualOutput map[string]map[string][]string
+	if err := json.Unmarshal([]byte(output), &actualOutput); err != nil {
+		t.Fatalf("Error unmarshaling actual output: %v", err)
+	}
+
+	if !reflect.DeepEqual(expectedOutput, actualOutput) {
+		t.Errorf("Expected output does not match actual output")
+	}
+}
@@ -7,8 +7,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/yonahd/kor/pkg/common"
@@ -18,6 +24,122 @@ import (
 //go:embed exceptions/daemonsets/daemonsets.json
 var daemonsetsConfig []byte
 
+// Tolerations the DaemonSet controller adds to every DaemonSet pod
+var daemonSetDefaultTolerations = []corev1.Toleration{
+	{Key: corev1.TaintNodeNotReady, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+	{Key: corev1.TaintNodeUnreachable, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+	{Key: corev1.TaintNodeDiskPressure, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	{Key: corev1.TaintNodeMemoryPressure, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	{Key: corev1.TaintNodePIDPressure, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	{Key: corev1.TaintNodeUnschedulable, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+}
+
+var nodeSelectorOperators = map[corev1.NodeSelectorOperator]selection.Operator{
+	corev1.NodeSelectorOpIn:           selection.In,
+	corev1.NodeSelectorOpNotIn:        selection.NotIn,
+	corev1.NodeSelectorOpExists:       selection.Exists,
+	corev1.NodeSelectorOpDoesNotExist: selection.DoesNotExist,
+	corev1.NodeSelectorOpGt:           selection.GreaterThan,
+	corev1.NodeSelectorOpLt:           selection.LessThan,
+}
+
+func matchesNodeSelectorRequirements(requirements []corev1.NodeSelectorRequirement, values labels.Set) bool {
+	for _, requirement := range requirements {
+		operator, ok := nodeSelectorOperators[requirement.Operator]
+		if !ok {
+			return false
+		}
+		labelRequirement, err := labels.NewRequirement(requirement.Key, operator, requirement.Values)
+		if err != nil || !labelRequirement.Matches(values) {
+			return false
+		}
+	}
+	return true
+}
+
+// Evaluate required node affinity, terms are ORed and an empty term matches no node
+func matchesRequiredNodeAffinity(affinity *corev1.Affinity, node corev1.Node) bool {
+	if affinity == nil || affinity.NodeAffinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true
+	}
+	for _, term := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+			continue
+		}
+		if matchesNodeSelectorRequirements(term.MatchExpressions, labels.Set(node.Labels)) &&
+			matchesNodeSelectorRequirements(term.MatchFields, labels.Set{"metadata.name": node.Name}) {
+			return true
+		}
+	}
+	return false
+}
+
+func untoleratedTaints(tolerations []corev1.Toleration, node corev1.Node) []string {
+	var untolerated []string
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		tolerated := false
+		for j := range tolerations {
+			if tolerations[j].ToleratesTaint(taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			untolerated = append(untolerated, taint.ToString())
+		}
+	}
+	return untolerated
+}
+
+// Explain why a DaemonSet has no pods scheduled by evaluating its pod template against the nodes
+func daemonSetSchedulingReason(daemonSet appsv1.DaemonSet, nodes []corev1.Node) string {
+	if len(nodes) == 0 {
+		return "DaemonSet has no replicas"
+	}
+
+	podSpec := daemonSet.Spec.Template.Spec
+	nodeSelector := labels.SelectorFromSet(podSpec.NodeSelector)
+	var selected []corev1.Node
+	for _, node := range nodes {
+		if nodeSelector.Matches(labels.Set(node.Labels)) {
+			selected = append(selected, node)
+		}
+	}
+	if len(selected) == 0 {
+		return fmt.Sprintf("DaemonSet nodeSelector %s matches no nodes", nodeSelector)
+	}
+
+	var affine []corev1.Node
+	for _, node := range selected {
+		if matchesRequiredNodeAffinity(podSpec.Affinity, node) {
+			affine = append(affine, node)
+		}
+	}
+	if len(affine) == 0 {
+		return fmt.Sprintf("DaemonSet required node affinity matches none of the %d nodes selected by its nodeSelector", len(selected))
+	}
+
+	tolerations := append(append([]corev1.Toleration{}, daemonSetDefaultTolerations...), podSpec.Tolerations...)
+	var taints []string
+	for _, node := range affine {
+		untolerated := untoleratedTaints(tolerations, node)
+		if len(untolerated) == 0 {
+			return fmt.Sprintf("DaemonSet can run on %d nodes but has no pods scheduled, its pods may be failing", len(affine))
+		}
+		for _, taint := range untolerated {
+			if !contains(taints, taint) {
+				taints = append(taints, taint)
+			}
+		}
+	}
+	sort.Strings(taints)
+	return fmt.Sprintf("DaemonSet does not tolerate the taints of any of the %d matching nodes: %s", len(affine), strings.Join(taints, ", "))
+}
+
 func processNamespaceDaemonSets(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
 	daemonSetsList, err := clientset.AppsV1().DaemonSets(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
 	if err != nil {
@@ -30,6 +152,8 @@ func processNamespaceDaemonSets(clientset kubernetes.Interface, namespace string
 	}
 
 	var daemonSetsWithoutReplicas []ResourceInfo
+	var nodes []corev1.Node
+	nodesListed := false
 
 	for _, daemonSet := range daemonSetsList.Items {
 		if pass, _ := filter.SetObject(&daemonSet).Run(filterOpts); pass {
@@ -52,7 +176,14 @@ func processNamespaceDaemonSets(clientset kubernetes.Interface, namespace string
 		}
 
 		if daemonSet.Status.CurrentNumberScheduled == 0 {
-			reason := "DaemonSet has no replicas"
+			if !nodesListed {
+				nodeList, err := clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+				if err != nil {
+					return nil, err
+				}
+				nodes, nodesListed = nodeList.Items, true
+			}
+			reason := daemonSetSchedulingReason(daemonSet, nodes)
 			daemonSetsWithoutReplicas = append(daemonSetsWithoutReplicas, ResourceInfo{Name: daemonSet.Name, Reason: reason})
 		}
 	}
@@ -80,6 +80,53 @@ func TestProcessNamespaceDaemonSets(t *testing.T) {
 	}
 }
 
+func TestDaemonSetSchedulingReason(t *testing.T) {
+	nodes := []corev1.Node{
+		{
+			ObjectMeta: v1.ObjectMeta{Name: "gpu-node", Labels: map[string]string{"pool": "gpu", "zone": "a"}},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}}},
+		},
+		{
+			ObjectMeta: v1.ObjectMeta{Name: "cpu-node", Labels: map[string]string{"pool": "cpu", "zone": "b"}},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{{Key: corev1.TaintNodeUnschedulable, Effect: corev1.TaintEffectNoSchedule}}},
+		},
+	}
+
+	requireZone := func(zone string) *corev1.Affinity {
+		return &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+					MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{zone}}},
+				}},
+			},
+		}}
+	}
+
+	tests := []struct {
+		name     string
+		spec     corev1.PodSpec
+		nodes    []corev1.Node
+		expected string
+	}{
+		{"no nodes", corev1.PodSpec{}, nil, "DaemonSet has no replicas"},
+		{"retired pool", corev1.PodSpec{NodeSelector: map[string]string{"pool": "retired"}}, nodes, "DaemonSet nodeSelector pool=retired matches no nodes"},
+		{"affinity", corev1.PodSpec{NodeSelector: map[string]string{"pool": "gpu"}, Affinity: requireZone("b")}, nodes, "DaemonSet required node affinity matches none of the 1 nodes selected by its nodeSelector"},
+		{"taints", corev1.PodSpec{Affinity: requireZone("a")}, nodes, "DaemonSet does not tolerate the taints of any of the 1 matching nodes: dedicated=gpu:NoSchedule"},
+		{"pods failing", corev1.PodSpec{NodeSelector: map[string]string{"pool": "cpu"}}, nodes, "DaemonSet can run on 1 nodes but has no pods scheduled, its pods may be failing"},
+		{"tolerated", corev1.PodSpec{Tolerations: []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "gpu"}}}, nodes, "DaemonSet can run on 2 nodes but has no pods scheduled, its pods may be failing"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			daemonSet := CreateTestDaemonSet(testNamespace, "ds", AppLabels, &appsv1.DaemonSetStatus{})
+			daemonSet.Spec.Template.Spec = test.spec
+			if reason := daemonSetSchedulingReason(*daemonSet, test.nodes); reason != test.expected {
+				t.Errorf("Expected %q, got %q", test.expected, reason)
+			}
+		})
+	}
+}
+
 func TestGetUnusedDaemonSetsStructured(t *testing.T) {
 	clientset := createTestDaemonSets(t)
 
@@ -12,8 +12,10 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	nodev1 "k8s.io/api/node/v1"
 	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -51,9 +53,15 @@ func DeleteResourceCmd() map[string]func(clientset kubernetes.Interface, namespa
 		"ClusterRole": func(clientset kubernetes.Interface, namespace, name string) error {
 			return clientset.RbacV1().ClusterRoles().Delete(context.TODO(), name, metav1.DeleteOptions{})
 		},
+		"ClusterRoleBinding": func(clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.RbacV1().ClusterRoleBindings().Delete(context.TODO(), name, metav1.DeleteOptions{})
+		},
 		"PVC": func(clientset kubernetes.Interface, namespace, name string) error {
 			return clientset.CoreV1().PersistentVolumeClaims(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
 		},
+		"StatefulSetPvc": func(clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.CoreV1().PersistentVolumeClaims(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		},
 		"StatefulSet": func(clientset kubernetes.Interface, namespace, name string) error {
 			return clientset.AppsV1().StatefulSets(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
 		},
@@ -69,6 +77,9 @@ func DeleteResourceCmd() map[string]func(clientset kubernetes.Interface, namespa
 		"Job": func(clientset kubernetes.Interface, namespace, name string) error {
 			return clientset.BatchV1().Jobs(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
 		},
+		"CronJob": func(clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.BatchV1().CronJobs(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		},
 		"ReplicaSet": func(clientset kubernetes.Interface, namespace, name string) error {
 			return clientset.AppsV1().ReplicaSets(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
 		},
@@ -78,12 +89,30 @@ func DeleteResourceCmd() map[string]func(clientset kubernetes.Interface, namespa
 		"StorageClass": func(clientset kubernetes.Interface, namespace, name string) error {
 			return clientset.StorageV1().StorageClasses().Delete(context.TODO(), name, metav1.DeleteOptions{})
 		},
+		"PriorityClass": func(clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.SchedulingV1().PriorityClasses().Delete(context.TODO(), name, metav1.DeleteOptions{})
+		},
+		"IngressClass": func(clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.NetworkingV1().IngressClasses().Delete(context.TODO(), name, metav1.DeleteOptions{})
+		},
+		"RuntimeClass": func(clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.NodeV1().RuntimeClasses().Delete(context.TODO(), name, metav1.DeleteOptions{})
+		},
 		"NetworkPolicy": func(clientset kubernetes.Interface, namespace, name string) error {
 			return clientset.NetworkingV1().NetworkPolicies(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
 		},
 		"RoleBinding": func(clientset kubernetes.Interface, namespace, name string) error {
 			return clientset.RbacV1().RoleBindings(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
 		},
+		"ResourceQuota": func(clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.CoreV1().ResourceQuotas(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		},
+		"LimitRange": func(clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.CoreV1().LimitRanges(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		},
+		"Namespace": func(clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.CoreV1().Namespaces().Delete(context.TODO(), name, metav1.DeleteOptions{})
+		},
 	}
 
 	return deleteResourceApiMap
@@ -153,7 +182,9 @@ func updateResource(clientset kubernetes.Interface, namespace, resourceType stri
 		return clientset.RbacV1().Roles(namespace).Update(context.TODO(), resource.(*rbacv1.Role), metav1.UpdateOptions{})
 	case "ClusterRole":
 		return clientset.RbacV1().ClusterRoles().Update(context.TODO(), resource.(*rbacv1.ClusterRole), metav1.UpdateOptions{})
-	case "PVC":
+	case "ClusterRoleBinding":
+		return clientset.RbacV1().ClusterRoleBindings().Update(context.TODO(), resource.(*rbacv1.ClusterRoleBinding), metav1.UpdateOptions{})
+	case "PVC", "StatefulSetPvc":
 		return clientset.CoreV1().PersistentVolumeClaims(namespace).Update(context.TODO(), resource.(*corev1.PersistentVolumeClaim), metav1.UpdateOptions{})
 	case "StatefulSet":
 		return clientset.AppsV1().StatefulSets(namespace).Update(context.TODO(), resource.(*appsv1.StatefulSet), metav1.UpdateOptions{})
@@ -165,16 +196,30 @@ func updateResource(clientset kubernetes.Interface, namespace, resourceType stri
 		return clientset.CoreV1().Pods(namespace).Update(context.TODO(), resource.(*corev1.Pod), metav1.UpdateOptions{})
 	case "Job":
 		return clientset.BatchV1().Jobs(namespace).Update(context.TODO(), resource.(*batchv1.Job), metav1.UpdateOptions{})
+	case "CronJob":
+		return clientset.BatchV1().CronJobs(namespace).Update(context.TODO(), resource.(*batchv1.CronJob), metav1.UpdateOptions{})
 	case "ReplicaSet":
 		return clientset.AppsV1().ReplicaSets(namespace).Update(context.TODO(), resource.(*appsv1.ReplicaSet), metav1.UpdateOptions{})
 	case "DaemonSet":
 		return clientset.AppsV1().DaemonSets(namespace).Update(context.TODO(), resource.(*appsv1.DaemonSet), metav1.UpdateOptions{})
 	case "StorageClass":
 		return clientset.StorageV1().StorageClasses().Update(context.TODO(), resource.(*storagev1.StorageClass), metav1.UpdateOptions{})
+	case "PriorityClass":
+		return clientset.SchedulingV1().PriorityClasses().Update(context.TODO(), resource.(*schedulingv1.PriorityClass), metav1.UpdateOptions{})
+	case "IngressClass":
+		return clientset.NetworkingV1().IngressClasses().Update(context.TODO(), resource.(*networkingv1.IngressClass), metav1.UpdateOptions{})
+	case "RuntimeClass":
+		return clientset.NodeV1().RuntimeClasses().Update(context.TODO(), resource.(*nodev1.RuntimeClass), metav1.UpdateOptions{})
 	case "NetworkPolicy":
 		return clientset.NetworkingV1().NetworkPolicies(namespace).Update(context.TODO(), resource.(*networkingv1.NetworkPolicy), metav1.UpdateOptions{})
+	case "ResourceQuota":
+		return clientset.CoreV1().ResourceQuotas(namespace).Update(context.TODO(), resource.(*corev1.ResourceQuota), metav1.UpdateOptions{})
+	case "LimitRange":
+		return clientset.CoreV1().LimitRanges(namespace).Update(context.TODO(), resource.(*corev1.LimitRange), metav1.UpdateOptions{})
 	case "RoleBinding":
 		return clientset.RbacV1().RoleBindings(namespace).Update(context.TODO(), resource.(*rbacv1.RoleBinding), metav1.UpdateOptions{})
+	case "Namespace":
+		return clientset.CoreV1().Namespaces().Update(context.TODO(), resource.(*corev1.Namespace), metav1.UpdateOptions{})
 	}
 	return nil, fmt.Errorf("resource type '%s' is not supported", resourceType)
 }
@@ -199,7 +244,9 @@ func getResource(clientset kubernetes.Interface, namespace, resourceType, resour
 		return clientset.RbacV1().Roles(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
 	case "ClusterRole":
 		return clientset.RbacV1().ClusterRoles().Get(context.TODO(), resourceName, metav1.GetOptions{})
-	case "PVC":
+	case "ClusterRoleBinding":
+		return clientset.RbacV1().ClusterRoleBindings().Get(context.TODO(), resourceName, metav1.GetOptions{})
+	case "PVC", "StatefulSetPvc":
 		return clientset.CoreV1().PersistentVolumeClaims(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
 	case "StatefulSet":
 		return clientset.AppsV1().StatefulSets(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
@@ -211,16 +258,30 @@ func getResource(clientset kubernetes.Interface, namespace, resourceType, resour
 		return clientset.CoreV1().Pods(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
 	case "Job":
 		return clientset.BatchV1().Jobs(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
+	case "CronJob":
+		return clientset.BatchV1().CronJobs(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
 	case "ReplicaSet":
 		return clientset.AppsV1().ReplicaSets(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
 	case "DaemonSet":
 		return clientset.AppsV1().DaemonSets(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
 	case "StorageClass":
 		return clientset.StorageV1().StorageClasses().Get(context.TODO(), resourceName, metav1.GetOptions{})
+	case "PriorityClass":
+		return clientset.SchedulingV1().PriorityClasses().Get(context.TODO(), resourceName, metav1.GetOptions{})
+	case "IngressClass":
+		return clientset.NetworkingV1().IngressClasses().Get(context.TODO(), resourceName, metav1.GetOptions{})
+	case "RuntimeClass":
+		return clientset.NodeV1().RuntimeClasses().Get(context.TODO(), resourceName, metav1.GetOptions{})
 	case "NetworkPolicy":
 		return clientset.NetworkingV1().NetworkPolicies(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
+	case "ResourceQuota":
+		return clientset.CoreV1().ResourceQuotas(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
+	case "LimitRange":
+		return clientset.CoreV1().LimitRanges(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
 	case "RoleBinding":
 		return clientset.RbacV1().RoleBindings(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
+	case "Namespace":
+		return clientset.CoreV1().Namespaces().Get(context.TODO(), resourceName, metav1.GetOptions{})
 	}
 	return nil, fmt.Errorf("resource type '%s' is not supported", resourceType)
 }
@@ -277,6 +338,52 @@ func DeleteResourceWithFinalizer(resources []ResourceInfo, dynamicClient dynamic
 	return remainingResources, nil
 }
 
+func DeleteDynamicResource(diff []ResourceInfo, dynamicClient dynamic.Interface, namespace string, gvr schema.GroupVersionResource, kind string, noInteractive bool) ([]ResourceInfo, error) {
+	deletedDiff := []ResourceInfo{}
+
+	for _, resource := range diff {
+		if !noInteractive {
+			fmt.Printf("Do you want to delete %s %s in namespace %s? (Y/N): ", kind, resource.Name, namespace)
+			var confirmation string
+			_, err := fmt.Scanf("%s\n", &confirmation)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to read input: %v\n", err)
+				continue
+			}
+
+			if strings.ToLower(confirmation) != "y" && strings.ToLower(confirmation) != "yes" {
+				deletedDiff = append(deletedDiff, resource)
+
+				fmt.Printf("Do you want flag the resource %s %s in namespace %s as In Use? (Y/N): ", kind, resource.Name, namespace)
+				var inUse string
+				_, err := fmt.Scanf("%s\n", &inUse)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Failed to read input: %v\n", err)
+					continue
+				}
+
+				if strings.ToLower(inUse) == "y" || strings.ToLower(inUse) == "yes" {
+					if err := FlagDynamicResource(dynamicClient, namespace, gvr, resource.Name); err != nil {
+						fmt.Fprintf(os.Stderr, "Failed to flag resource %s %s in namespace %s as In Use: %v\n", kind, resource.Name, namespace, err)
+					}
+				}
+				continue
+			}
+		}
+
+		fmt.Printf("Deleting %s %s in namespace %s\n", kind, resource.Name, namespace)
+		if err := dynamicClient.Resource(gvr).Namespace(namespace).Delete(context.TODO(), resource.Name, metav1.DeleteOptions{}); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to delete %s %s in namespace %s: %v\n", kind, resource.Name, namespace, err)
+			continue
+		}
+		deletedResource := resource
+		deletedResource.Name += "-DELETED"
+		deletedDiff = append(deletedDiff, deletedResource)
+	}
+
+	return deletedDiff, nil
+}
+
 func DeleteResource(diff []ResourceInfo, clientset kubernetes.Interface, namespace, resourceType string, noInteractive bool) ([]ResourceInfo, error) {
 	deletedDiff := []ResourceInfo{}
 
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -33,7 +34,7 @@ func processNamespaceDeployments(clientset kubernetes.Interface, namespace strin
 			continue
 		}
 
-		if *deployment.Spec.Replicas == 0 {
+		if desiredReplicas(deployment.Spec.Replicas) == 0 {
 			reason := "Deployment has no replicas"
 			deploymentsWithoutReplicas = append(deploymentsWithoutReplicas, ResourceInfo{Name: deployment.Name, Reason: reason})
 		}
@@ -42,6 +43,41 @@ func processNamespaceDeployments(clientset kubernetes.Interface, namespace strin
 	return deploymentsWithoutReplicas, nil
 }
 
+// Broken Deployments still want replicas, they are reported apart from unused ones and never deleted
+func processNamespaceBrokenDeployments(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	if !filterOpts.IncludeBroken {
+		return nil, nil
+	}
+
+	deploymentsList, err := clientset.AppsV1().Deployments(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := retrieveBrokenWorkloadPods(clientset, namespace, filterOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	var brokenDeployments []ResourceInfo
+
+	for _, deployment := range deploymentsList.Items {
+		if pass, _ := filter.SetObject(&deployment).Run(filterOpts); pass {
+			continue
+		}
+
+		if deployment.Labels["kor/used"] == "false" || desiredReplicas(deployment.Spec.Replicas) == 0 {
+			continue
+		}
+
+		if reason := brokenDeploymentReason(deployment, pods, filterOpts.BrokenAfter, time.Now()); reason != "" {
+			brokenDeployments = append(brokenDeployments, ResourceInfo{Name: deployment.Name, Reason: reason})
+		}
+	}
+
+	return brokenDeployments, nil
+}
+
 func GetUnusedDeployments(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
 	resources := make(map[string]map[string][]ResourceInfo)
 	for _, namespace := range filterOpts.Namespaces(clientset) {
@@ -55,12 +91,22 @@ func GetUnusedDeployments(filterOpts *filters.Options, clientset kubernetes.Inte
 				fmt.Fprintf(os.Stderr, "Failed to delete Deployment %s in namespace %s: %v\n", diff, namespace, err)
 			}
 		}
+		broken, err := processNamespaceBrokenDeployments(clientset, namespace, filterOpts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to check broken Deployments in namespace %s: %v\n", namespace, err)
+		}
 		switch opts.GroupBy {
 		case "namespace":
 			resources[namespace] = make(map[string][]ResourceInfo)
 			resources[namespace]["Deployment"] = diff
+			if len(broken) > 0 {
+				resources[namespace]["BrokenDeployment"] = broken
+			}
 		case "resource":
 			appendResources(resources, "Deployment", namespace, diff)
+			if len(broken) > 0 {
+				appendResources(resources, "BrokenDeployment", namespace, broken)
+			}
 		}
 	}
 
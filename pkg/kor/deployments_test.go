@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"reflect"
 	"testing"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -72,6 +73,79 @@ func TestProcessNamespaceDeployments(t *testing.T) {
 	}
 }
 
+func TestProcessNamespaceBrokenDeployments(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	now := time.Now()
+	longAgo := v1.NewTime(now.Add(-30 * 24 * time.Hour))
+	recently := v1.NewTime(now.Add(-time.Hour))
+
+	createDeployment := func(name string, mutate func(deployment *appsv1.Deployment)) {
+		deployment := CreateTestDeployment(testNamespace, name, 1, map[string]string{"app": name})
+		deployment.Spec.Selector = &v1.LabelSelector{MatchLabels: map[string]string{"app": name}}
+		deployment.CreationTimestamp = longAgo
+		mutate(deployment)
+		_, err := clientset.AppsV1().Deployments(testNamespace).Create(context.TODO(), deployment, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake deployment: %v", err)
+		}
+	}
+	createPod := func(name, app string, status corev1.PodStatus) {
+		pod := CreateTestPod(testNamespace, name, "", nil, map[string]string{"app": app})
+		pod.Status = status
+		_, err := clientset.CoreV1().Pods(testNamespace).Create(context.TODO(), pod, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake pod: %v", err)
+		}
+	}
+
+	createDeployment("default-replicas", func(deployment *appsv1.Deployment) {
+		deployment.Spec.Replicas = nil
+		deployment.Status.AvailableReplicas = 1
+	})
+	createDeployment("paused", func(deployment *appsv1.Deployment) {
+		deployment.Spec.Paused = true
+	})
+	createDeployment("deadline-exceeded", func(deployment *appsv1.Deployment) {
+		deployment.Status.Conditions = []appsv1.DeploymentCondition{
+			{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded", LastUpdateTime: longAgo},
+		}
+		deployment.Status.AvailableReplicas = 1
+	})
+	createDeployment("image-pull", func(deployment *appsv1.Deployment) {})
+	createDeployment("unavailable", func(deployment *appsv1.Deployment) {
+		deployment.Status.Conditions = []appsv1.DeploymentCondition{
+			{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionFalse, LastTransitionTime: longAgo},
+		}
+	})
+	createDeployment("recently-unavailable", func(deployment *appsv1.Deployment) {
+		deployment.Status.Conditions = []appsv1.DeploymentCondition{
+			{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionFalse, LastTransitionTime: recently},
+		}
+	})
+
+	imagePullBackOff := corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+		{Image: "registry.example.com/app:missing", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}},
+	}}
+	createPod("image-pull-1", "image-pull", imagePullBackOff)
+	createPod("image-pull-2", "image-pull", imagePullBackOff)
+
+	filterOpts := &filters.Options{IncludeBroken: true, BrokenAfter: 7 * 24 * time.Hour}
+	brokenDeployments, err := processNamespaceBrokenDeployments(clientset, testNamespace, filterOpts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []ResourceInfo{
+		{Name: "deadline-exceeded", Reason: "Deployment rollout has exceeded its progress deadline since " + longAgo.UTC().Format(time.RFC3339)},
+		{Name: "image-pull", Reason: "Deployment image registry.example.com/app:missing cannot be pulled on any pod (ImagePullBackOff)"},
+		{Name: "paused", Reason: "Deployment rollout is paused"},
+		{Name: "unavailable", Reason: "Deployment has had no available replicas since " + longAgo.UTC().Format(time.RFC3339)},
+	}
+	if !reflect.DeepEqual(brokenDeployments, expected) {
+		t.Errorf("Expected %v, got %v", expected, brokenDeployments)
+	}
+}
+
 func TestGetUnusedDeploymentsStructured(t *testing.T) {
 	clientset := createTestDeployments(t)
 
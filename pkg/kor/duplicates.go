@@ -0,0 +1,245 @@
+package kor
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/strings/slices"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+// duplicateCopy is one ConfigMap or Secret in a group of objects holding identical data
+type duplicateCopy struct {
+	namespace string
+	name      string
+}
+
+func (c duplicateCopy) String() string {
+	return c.namespace + "/" + c.name
+}
+
+// Hash data and binaryData with sorted keys, so values are compared without ever being printed
+func hashObjectData(objectType string, data map[string]string, binaryData map[string][]byte) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "type:%s\x00", objectType)
+
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(hash, "data:%s\x00%d\x00%s\x00", key, len(data[key]), data[key])
+	}
+
+	keys = keys[:0]
+	for key := range binaryData {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(hash, "binaryData:%s\x00%d\x00", key, len(binaryData[key]))
+		hash.Write(binaryData[key])
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func describeDuplicateCopies(copies []duplicateCopy, self duplicateCopy) string {
+	const maxListed = 5
+	var others []string
+	for _, duplicate := range copies {
+		if duplicate != self {
+			others = append(others, duplicate.String())
+		}
+	}
+	if len(others) > maxListed {
+		return fmt.Sprintf("%s and %d more", strings.Join(others[:maxListed], ", "), len(others)-maxListed)
+	}
+	return strings.Join(others, ", ")
+}
+
+// Hash the ConfigMaps and Secrets of the given namespaces by kind
+func retrieveDataHashes(clientset kubernetes.Interface, namespaces []string, filterOpts *filters.Options) (map[string]map[string][]duplicateCopy, error) {
+	hashes := map[string]map[string][]duplicateCopy{
+		"ConfigMap": make(map[string][]duplicateCopy),
+		"Secret":    make(map[string][]duplicateCopy),
+	}
+
+	configMapExceptions, err := unmarshalConfig(configMapsConfig)
+	if err != nil {
+		return nil, err
+	}
+	secretExceptions, err := unmarshalConfig(secretsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, namespace := range namespaces {
+		configMaps, err := clientset.CoreV1().ConfigMaps(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+		if err != nil {
+			return nil, err
+		}
+		for _, configMap := range configMaps.Items {
+			if len(configMap.Data) == 0 && len(configMap.BinaryData) == 0 {
+				continue
+			}
+			if pass, _ := filter.SetObject(&configMap).Run(filterOpts); pass {
+				continue
+			}
+			if exceptionFound, err := isResourceException(configMap.Name, configMap.Namespace, configMapExceptions.ExceptionConfigMaps); err != nil {
+				return nil, err
+			} else if exceptionFound {
+				continue
+			}
+			hash := hashObjectData("", configMap.Data, configMap.BinaryData)
+			hashes["ConfigMap"][hash] = append(hashes["ConfigMap"][hash], duplicateCopy{namespace, configMap.Name})
+		}
+
+		secrets, err := clientset.CoreV1().Secrets(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+		if err != nil {
+			return nil, err
+		}
+		for _, secret := range secrets.Items {
+			if len(secret.Data) == 0 || slices.Contains(exceptionSecretTypes, string(secret.Type)) {
+				continue
+			}
+			if pass, _ := filter.SetObject(&secret).Run(filterOpts); pass {
+				continue
+			}
+			if exceptionFound, err := isResourceException(secret.Name, secret.Namespace, secretExceptions.ExceptionSecrets); err != nil {
+				return nil, err
+			} else if exceptionFound {
+				continue
+			}
+			hash := hashObjectData(string(secret.Type), nil, secret.Data)
+			hashes["Secret"][hash] = append(hashes["Secret"][hash], duplicateCopy{namespace, secret.Name})
+		}
+	}
+
+	return hashes, nil
+}
+
+// Group identical objects under stable IDs, the hashes themselves are never part of the output
+func groupDuplicates(hashes map[string]map[string][]duplicateCopy, unused map[string]map[string]string) map[string]map[string][]ResourceInfo {
+	duplicates := make(map[string]map[string][]ResourceInfo) //map[namespace]map[group][]copies
+
+	for _, kind := range []string{"ConfigMap", "Secret"} {
+		var groups [][]duplicateCopy
+		for _, copies := range hashes[kind] {
+			if len(copies) < 2 {
+				continue
+			}
+			sort.Slice(copies, func(i, j int) bool {
+				return copies[i].String() < copies[j].String()
+			})
+			groups = append(groups, copies)
+		}
+		sort.Slice(groups, func(i, j int) bool {
+			return groups[i][0].String() < groups[j][0].String()
+		})
+
+		for i, copies := range groups {
+			group := fmt.Sprintf("%sDuplicates-%d", kind, i+1)
+			for _, duplicate := range copies {
+				reason := fmt.Sprintf("Same data as %s", describeDuplicateCopies(copies, duplicate))
+				if unusedReason, isUnused := unused[kind][duplicate.String()]; isUnused {
+					reason = fmt.Sprintf("%s, this copy is unused: %s", reason, unusedReason)
+				}
+				if duplicates[duplicate.namespace] == nil {
+					duplicates[duplicate.namespace] = make(map[string][]ResourceInfo)
+				}
+				duplicates[duplicate.namespace][group] = append(duplicates[duplicate.namespace][group], ResourceInfo{Name: duplicate.name, Reason: reason})
+			}
+		}
+	}
+
+	return duplicates
+}
+
+func retrieveDuplicates(clientset kubernetes.Interface, namespaces []string, filterOpts *filters.Options) (map[string]map[string][]ResourceInfo, error) {
+	hashes, err := retrieveDataHashes(clientset, namespaces, filterOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	unused := map[string]map[string]string{
+		"ConfigMap": make(map[string]string),
+		"Secret":    make(map[string]string),
+	}
+	for _, namespace := range namespaces {
+		unusedConfigMaps, err := processNamespaceCM(clientset, namespace, filterOpts)
+		if err != nil {
+			return nil, err
+		}
+		for _, configMap := range unusedConfigMaps {
+			unused["ConfigMap"][namespace+"/"+configMap.Name] = configMap.Reason
+		}
+
+		unusedSecrets, err := processNamespaceSecret(clientset, namespace, filterOpts)
+		if err != nil {
+			return nil, err
+		}
+		for _, secret := range unusedSecrets {
+			unused["Secret"][namespace+"/"+secret.Name] = secret.Reason
+		}
+	}
+
+	return groupDuplicates(hashes, unused), nil
+}
+
+func GetDuplicates(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	if opts.DeleteFlag {
+		fmt.Fprintf(os.Stderr, "Deleting duplicates is not supported, ignoring --delete flag\n")
+	}
+
+	duplicates, err := retrieveDuplicates(clientset, filterOpts.Namespaces(clientset), filterOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to process duplicates: %v\n", err)
+	}
+
+	resources := make(map[string]map[string][]ResourceInfo)
+	for namespace, groups := range duplicates {
+		for group, copies := range groups {
+			switch opts.GroupBy {
+			case "namespace":
+				if resources[namespace] == nil {
+					resources[namespace] = make(map[string][]ResourceInfo)
+				}
+				resources[namespace][group] = copies
+			case "resource":
+				appendResources(resources, group, namespace, copies)
+			}
+		}
+	}
+
+	var outputBuffer bytes.Buffer
+	var jsonResponse []byte
+	switch outputFormat {
+	case "table":
+		outputBuffer = FormatOutput(resources, opts)
+	case "json", "yaml":
+		var err error
+		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
+			return "", err
+		}
+	}
+
+	duplicatesReport, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
+	if err != nil {
+		fmt.Printf("err: %v\n", err)
+	}
+
+	return duplicatesReport, nil
+}
@@ -0,0 +1,109 @@
+package kor
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func TestHashObjectData(t *testing.T) {
+	data := map[string]string{"a": "1", "b": "2"}
+	if hashObjectData("", data, nil) != hashObjectData("", map[string]string{"b": "2", "a": "1"}, nil) {
+		t.Error("Expected the hash to be independent of key order")
+	}
+	if hashObjectData("", map[string]string{"ab": ""}, nil) == hashObjectData("", map[string]string{"a": "b"}, nil) {
+		t.Error("Expected keys and values not to be ambiguous")
+	}
+	if hashObjectData("", nil, map[string][]byte{"a": []byte("1")}) == hashObjectData("", data, nil) {
+		t.Error("Expected data and binaryData to be hashed apart")
+	}
+	if hashObjectData("Opaque", nil, map[string][]byte{"a": []byte("1")}) == hashObjectData("kubernetes.io/basic-auth", nil, map[string][]byte{"a": []byte("1")}) {
+		t.Error("Expected Secret types to be hashed apart")
+	}
+}
+
+func TestRetrieveDuplicates(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	otherNamespace := "other-namespace"
+
+	createConfigMap := func(namespace, name string, data map[string]string) {
+		configMap := CreateTestConfigmap(namespace, name, AppLabels)
+		configMap.Data = data
+		_, err := clientset.CoreV1().ConfigMaps(namespace).Create(context.TODO(), configMap, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake configmap: %v", err)
+		}
+	}
+	createSecret := func(namespace, name, password string) {
+		secret := CreateTestSecret(namespace, name, AppLabels)
+		secret.Data = map[string][]byte{"password": []byte(password)}
+		_, err := clientset.CoreV1().Secrets(namespace).Create(context.TODO(), secret, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake secret: %v", err)
+		}
+	}
+
+	appConfig := map[string]string{"LOG_LEVEL": "debug", "PORT": "8080"}
+	createConfigMap(testNamespace, "app-config", appConfig)
+	createConfigMap(testNamespace, "app-config-v2", appConfig)
+	createConfigMap(otherNamespace, "app-config", appConfig)
+	createConfigMap(testNamespace, "other-config", map[string]string{"PORT": "9090"})
+	createSecret(testNamespace, "db-password", "s3cr3t")
+	createSecret(otherNamespace, "db-password", "s3cr3t")
+	createSecret(otherNamespace, "api-token", "t0k3n")
+
+	pod := CreateTestPod(testNamespace, "pod", "", []corev1.Volume{
+		{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "app-config"}}}},
+		{Name: "secret", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "db-password"}}},
+	}, AppLabels)
+	_, err := clientset.CoreV1().Pods(testNamespace).Create(context.TODO(), pod, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake pod: %v", err)
+	}
+
+	duplicates, err := retrieveDuplicates(clientset, []string{testNamespace, otherNamespace}, &filters.Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := map[string]map[string][]ResourceInfo{
+		otherNamespace: {
+			"ConfigMapDuplicates-1": {
+				{Name: "app-config", Reason: "Same data as test-namespace/app-config, test-namespace/app-config-v2, this copy is unused: ConfigMap is not used in any pod or container"},
+			},
+			"SecretDuplicates-1": {
+				{Name: "db-password", Reason: "Same data as test-namespace/db-password, this copy is unused: Secret is not used in any pod, container, or ingress"},
+			},
+		},
+		testNamespace: {
+			"ConfigMapDuplicates-1": {
+				{Name: "app-config", Reason: "Same data as other-namespace/app-config, test-namespace/app-config-v2"},
+				{Name: "app-config-v2", Reason: "Same data as other-namespace/app-config, test-namespace/app-config, this copy is unused: ConfigMap is not used in any pod or container"},
+			},
+			"SecretDuplicates-1": {
+				{Name: "db-password", Reason: "Same data as other-namespace/db-password"},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(duplicates, expected) {
+		t.Errorf("Expected %v, got %v", expected, duplicates)
+	}
+
+	for _, groups := range duplicates {
+		for _, copies := range groups {
+			for _, duplicate := range copies {
+				if strings.Contains(duplicate.Reason, "s3cr3t") {
+					t.Errorf("Secret value leaked in reason %q", duplicate.Reason)
+				}
+			}
+		}
+	}
+}
@@ -27,10 +27,18 @@ var (
 		},
 		[]string{"kind", "namespace", "resourceName"},
 	)
+	tlsCertificateExpiryGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kubernetes_tls_certificate_expiry_timestamp_seconds",
+			Help: "Expiry time of expired or soon to expire certificates in kubernetes.io/tls Secrets",
+		},
+		[]string{"namespace", "resourceName", "subject", "status"},
+	)
 )
 
 func init() {
 	prometheus.MustRegister(orphanedResourcesCounter)
+	prometheus.MustRegister(tlsCertificateExpiryGauge)
 }
 
 // TODO: add option to change port / url !?
@@ -75,11 +83,51 @@ func exportMetrics(filterOptions *filters.Options, clientset kubernetes.Interfac
 					}
 				}
 			}
+
+			if exportsSecrets(resourceList) {
+				exportTlsCertificates(filterOptions, clientset)
+			}
 			time.Sleep(time.Duration(exporterIntervalValue) * time.Minute)
 		}
 	}
 }
 
+func exportsSecrets(resourceList []string) bool {
+	if len(resourceList) == 0 {
+		return true
+	}
+	for _, resource := range resourceList {
+		switch resource {
+		case "all", "secret", "secrets":
+			return true
+		}
+	}
+	return false
+}
+
+func exportTlsCertificates(filterOptions *filters.Options, clientset kubernetes.Interface) {
+	tlsCertificateExpiryGauge.Reset()
+	now := time.Now()
+	for _, namespace := range filterOptions.Namespaces(clientset) {
+		tlsSecrets, err := retrieveTlsSecrets(clientset, namespace, filterOptions)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to check TLS certificates in namespace %s: %v\n", namespace, err)
+			continue
+		}
+		for _, secret := range tlsSecrets {
+			issue := checkTlsCertificate(secret, filterOptions.CertExpiryWindow, now)
+			if issue == nil {
+				continue
+			}
+			status := "expiring"
+			if issue.expired {
+				status = "expired"
+			}
+			tlsCertificateExpiryGauge.WithLabelValues(namespace, issue.secret, issue.subject, status).Set(float64(issue.notAfter.Unix()))
+		}
+	}
+}
+
 func getUnusedResources(filterOptions *filters.Options, clientset kubernetes.Interface, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts, resourceList []string) (string, error) {
 	if len(resourceList) == 0 || (len(resourceList) == 1 && resourceList[0] == "all") {
 		return GetUnusedAll(filterOptions, clientset, apiExtClient, dynamicClient, outputFormat, opts)
@@ -2,12 +2,12 @@ package kor
 
 import (
 	"bytes"
-	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
@@ -28,44 +28,22 @@ func CheckFinalizers(finalizers []string, deletionTimestamp *metav1.Time) bool {
 func retrievePendingDeletionResources(resourceTypes []*metav1.APIResourceList, dynamicClient dynamic.Interface, filterOpts *filters.Options) (map[string]map[schema.GroupVersionResource][]ResourceInfo, error) {
 	pendingDeletionResources := make(map[string]map[schema.GroupVersionResource][]ResourceInfo) //map[namespace]map[gvr][]resourceNames
 
-	for _, apiResourceList := range resourceTypes {
-		gv, err := schema.ParseGroupVersion(apiResourceList.GroupVersion)
-		if err != nil {
-			return pendingDeletionResources, err
+	_, err := walkDiscoveredResources(resourceTypes, dynamicClient, metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels}, func(gvr schema.GroupVersionResource, _ metav1.APIResource, item unstructured.Unstructured) {
+		if pass, _ := filter.SetObject(&item).Run(filterOpts); pass {
+			return
 		}
-
-		for _, resourceType := range apiResourceList.APIResources {
-
-			if slices.Contains(resourceType.Verbs, "list") {
-
-				gvr := gv.WithResource(resourceType.Name)
-				resourceList, err := dynamicClient.
-					Resource(gvr).
-					Namespace(metav1.NamespaceAll).
-					List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
-				if err != nil {
-					fmt.Printf("Error listing resources for GVR %s: %v\n", apiResourceList.GroupVersion, err)
-					continue
-				}
-				for _, item := range resourceList.Items {
-					if pass, _ := filter.SetObject(&item).Run(filterOpts); pass {
-						continue
-					}
-					if CheckFinalizers(item.GetFinalizers(), item.GetDeletionTimestamp()) {
-						if pendingDeletionResources[item.GetNamespace()] == nil {
-							pendingDeletionResources[item.GetNamespace()] = make(map[schema.GroupVersionResource][]ResourceInfo)
-						}
-						finalizerInfo := ResourceInfo{
-							Name:   item.GetName(),
-							Reason: "Pending deletion waiting for finalizers",
-						}
-						pendingDeletionResources[item.GetNamespace()][gvr] = append(pendingDeletionResources[item.GetNamespace()][gvr], finalizerInfo)
-					}
-				}
+		if CheckFinalizers(item.GetFinalizers(), item.GetDeletionTimestamp()) {
+			if pendingDeletionResources[item.GetNamespace()] == nil {
+				pendingDeletionResources[item.GetNamespace()] = make(map[schema.GroupVersionResource][]ResourceInfo)
 			}
+			finalizerInfo := ResourceInfo{
+				Name:   item.GetName(),
+				Reason: "Pending deletion waiting for finalizers",
+			}
+			pendingDeletionResources[item.GetNamespace()][gvr] = append(pendingDeletionResources[item.GetNamespace()][gvr], finalizerInfo)
 		}
-	}
-	return pendingDeletionResources, nil
+	})
+	return pendingDeletionResources, err
 }
 
 func getResourcesWithFinalizersPendingDeletion(clientset kubernetes.Interface, dynamicClient dynamic.Interface, filterOpts *filters.Options) (map[string]map[schema.GroupVersionResource][]ResourceInfo, error) {
@@ -6,57 +6,196 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
-	"k8s.io/utils/strings/slices"
 
 	"github.com/yonahd/kor/pkg/common"
 	"github.com/yonahd/kor/pkg/filters"
 )
 
-func getDeploymentNames(clientset kubernetes.Interface, namespace string) ([]string, error) {
-	deployments, err := clientset.AppsV1().Deployments(namespace).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return nil, err
+// Resolves HPA scale targets of any kind through discovery, caching each API version
+type scaleTargetResolver struct {
+	clientset     kubernetes.Interface
+	dynamicClient dynamic.Interface
+	resources     map[string]*metav1.APIResourceList
+}
+
+func newScaleTargetResolver(clientset kubernetes.Interface, dynamicClient dynamic.Interface) *scaleTargetResolver {
+	return &scaleTargetResolver{
+		clientset:     clientset,
+		dynamicClient: dynamicClient,
+		resources:     make(map[string]*metav1.APIResourceList),
 	}
-	names := make([]string, 0, len(deployments.Items))
-	for _, deployment := range deployments.Items {
-		names = append(names, deployment.Name)
+}
+
+// Find the resource serving a kind and whether it has a scale subresource, nil when the API version is not served
+func (r *scaleTargetResolver) resourceFor(apiVersion, kind string) (*metav1.APIResource, bool, bool, error) {
+	resourceList, cached := r.resources[apiVersion]
+	if !cached {
+		var err error
+		resourceList, err = r.clientset.Discovery().ServerResourcesForGroupVersion(apiVersion)
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, false, false, err
+		}
+		r.resources[apiVersion] = resourceList
 	}
-	return names, nil
+	if resourceList == nil {
+		return nil, false, false, nil
+	}
+
+	var resource *metav1.APIResource
+	hasScale := false
+	for i := range resourceList.APIResources {
+		apiResource := &resourceList.APIResources[i]
+		if apiResource.Kind == kind && !strings.Contains(apiResource.Name, "/") {
+			resource = apiResource
+		}
+	}
+	if resource != nil {
+		for _, apiResource := range resourceList.APIResources {
+			if apiResource.Name == resource.Name+"/scale" {
+				hasScale = true
+			}
+		}
+	}
+	return resource, hasScale, true, nil
 }
 
-func getStatefulSetNames(clientset kubernetes.Interface, namespace string) ([]string, error) {
-	statefulSets, err := clientset.AppsV1().StatefulSets(namespace).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return nil, err
+// API versions of the built-in scalable kinds, used when a scale target omits its apiVersion
+var defaultScaleTargetAPIVersions = map[string]string{
+	"Deployment":            "apps/v1",
+	"StatefulSet":           "apps/v1",
+	"ReplicaSet":            "apps/v1",
+	"ReplicationController": "v1",
+}
+
+// Fill in the apiVersion of a scale target that omits it, false when the kind is not a built-in one
+func resolveScaleTarget(target autoscalingv2.CrossVersionObjectReference) (autoscalingv2.CrossVersionObjectReference, bool) {
+	if target.APIVersion == "" {
+		apiVersion, known := defaultScaleTargetAPIVersions[target.Kind]
+		if !known {
+			return target, false
+		}
+		target.APIVersion = apiVersion
 	}
-	names := make([]string, 0, len(statefulSets.Items))
-	for _, statefulSet := range statefulSets.Items {
-		names = append(names, statefulSet.Name)
+	return target, true
+}
+
+// Identify a scale target by group rather than version, every version of a group serves the same objects
+func scaleTargetKey(target autoscalingv2.CrossVersionObjectReference) string {
+	gv, err := schema.ParseGroupVersion(target.APIVersion)
+	if err != nil {
+		return target.APIVersion + "/" + target.Kind + "/" + target.Name
 	}
-	return names, nil
+	return gv.Group + "/" + target.Kind + "/" + target.Name
 }
 
-func processNamespaceHpas(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
-	deploymentNames, err := getDeploymentNames(clientset, namespace)
+// Explain why the scale target of an HPA cannot be scaled, or return an empty string
+func (r *scaleTargetResolver) targetReason(namespace string, target autoscalingv2.CrossVersionObjectReference) (string, error) {
+	target, resolved := resolveScaleTarget(target)
+	if !resolved {
+		return fmt.Sprintf("Scale target %s %s has no apiVersion and is unresolvable", target.Kind, target.Name), nil
+	}
+
+	resource, hasScale, served, err := r.resourceFor(target.APIVersion, target.Kind)
 	if err != nil {
-		return nil, err
+		return "", err
+	}
+	if !served {
+		return fmt.Sprintf("Scale target API version %s is not served", target.APIVersion), nil
+	}
+	if resource == nil {
+		return fmt.Sprintf("Scale target kind %s is not served by %s", target.Kind, target.APIVersion), nil
+	}
+	if !hasScale {
+		return fmt.Sprintf("Scale target kind %s has no scale subresource", target.Kind), nil
 	}
 
-	statefulsetNames, err := getStatefulSetNames(clientset, namespace)
+	gv, err := schema.ParseGroupVersion(target.APIVersion)
 	if err != nil {
-		return nil, err
+		return "", err
+	}
+	gvr := gv.WithResource(resource.Name)
+
+	scale, err := r.dynamicClient.Resource(gvr).Namespace(namespace).Get(context.TODO(), target.Name, metav1.GetOptions{}, "scale")
+	if errors.IsNotFound(err) {
+		return fmt.Sprintf("Scale target %s %s does not exist", target.Kind, target.Name), nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// The HPA controller does not scale targets whose replicas were set to zero
+	replicas, _, _ := unstructured.NestedInt64(scale.Object, "spec", "replicas")
+	if replicas == 0 {
+		return fmt.Sprintf("Scale target %s %s is scaled to zero, autoscaling is disabled", target.Kind, target.Name), nil
+	}
+
+	return "", nil
+}
+
+// Explain why an HPA has been unable to compute its metrics for longer than failingAfter, or return an empty string
+func failingMetricsReason(hpa autoscalingv2.HorizontalPodAutoscaler, failingAfter time.Duration, now time.Time) string {
+	if failingAfter <= 0 {
+		return ""
+	}
+	for _, condition := range hpa.Status.Conditions {
+		if condition.Type != autoscalingv2.ScalingActive || condition.Status != corev1.ConditionFalse {
+			continue
+		}
+		if now.Sub(condition.LastTransitionTime.Time) >= failingAfter {
+			return fmt.Sprintf("HPA metrics have been failing since %s (%s)", condition.LastTransitionTime.UTC().Format(time.RFC3339), condition.Reason)
+		}
+	}
+	return ""
+}
+
+// Pick the HPA kept for each scale target: the oldest one, then the first by name
+func retrieveScaleTargetOwners(hpas []autoscalingv2.HorizontalPodAutoscaler, filterOpts *filters.Options) map[string]autoscalingv2.HorizontalPodAutoscaler {
+	owners := make(map[string]autoscalingv2.HorizontalPodAutoscaler)
+	for _, hpa := range hpas {
+		if pass, _ := filter.SetObject(&hpa).Run(filterOpts); pass {
+			continue
+		}
+		if hpa.Labels["kor/used"] == "false" {
+			continue
+		}
+		target, resolved := resolveScaleTarget(hpa.Spec.ScaleTargetRef)
+		if !resolved {
+			continue
+		}
+		key := scaleTargetKey(target)
+		owner, found := owners[key]
+		if !found || hpa.CreationTimestamp.Before(&owner.CreationTimestamp) ||
+			(hpa.CreationTimestamp.Equal(&owner.CreationTimestamp) && hpa.Name < owner.Name) {
+			owners[key] = hpa
+		}
 	}
+	return owners
+}
 
+// processNamespaceHpas returns the unused HPAs, and separately the HPAs sharing their scale target with an older one
+func processNamespaceHpas(clientset kubernetes.Interface, dynamicClient dynamic.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, []ResourceInfo, error) {
 	hpas, err := clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	var unusedHpas []ResourceInfo
+	resolver := newScaleTargetResolver(clientset, dynamicClient)
+	owners := retrieveScaleTargetOwners(hpas.Items, filterOpts)
+	now := time.Now()
+
+	var unusedHpas, duplicateHpas []ResourceInfo
 	for _, hpa := range hpas.Items {
 		if pass, _ := filter.SetObject(&hpa).Run(filterOpts); pass {
 			continue
@@ -67,24 +206,36 @@ func processNamespaceHpas(clientset kubernetes.Interface, namespace string, filt
 			continue
 		}
 
-		switch hpa.Spec.ScaleTargetRef.Kind {
-		case "Deployment":
-			if !slices.Contains(deploymentNames, hpa.Spec.ScaleTargetRef.Name) {
-				unusedHpas = append(unusedHpas, ResourceInfo{Name: hpa.Name, Reason: "Scale target Deployment does not exist"})
-			}
-		case "StatefulSet":
-			if !slices.Contains(statefulsetNames, hpa.Spec.ScaleTargetRef.Name) {
-				unusedHpas = append(unusedHpas, ResourceInfo{Name: hpa.Name, Reason: "Scale target StatefulSet does not exist"})
-			}
+		target := hpa.Spec.ScaleTargetRef
+		// A target that cannot be checked, e.g. forbidden to kor, says nothing about the other HPAs
+		reason, err := resolver.targetReason(namespace, target)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to check scale target %s %s of HPA %s in namespace %s: %v\n", target.Kind, target.Name, hpa.Name, namespace, err)
+			continue
+		}
+		if reason != "" {
+			unusedHpas = append(unusedHpas, ResourceInfo{Name: hpa.Name, Reason: reason})
+			continue
+		}
+
+		resolvedTarget, _ := resolveScaleTarget(target)
+		if owner := owners[scaleTargetKey(resolvedTarget)]; owner.Name != hpa.Name {
+			reason := fmt.Sprintf("Scale target %s %s is also targeted by HPA %s", target.Kind, target.Name, owner.Name)
+			duplicateHpas = append(duplicateHpas, ResourceInfo{Name: hpa.Name, Reason: reason})
+			continue
+		}
+
+		if reason := failingMetricsReason(hpa, filterOpts.HpaFailingAfter, now); reason != "" {
+			unusedHpas = append(unusedHpas, ResourceInfo{Name: hpa.Name, Reason: reason})
 		}
 	}
-	return unusedHpas, nil
+	return unusedHpas, duplicateHpas, nil
 }
 
-func GetUnusedHpas(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+func GetUnusedHpas(filterOpts *filters.Options, clientset kubernetes.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts) (string, error) {
 	resources := make(map[string]map[string][]ResourceInfo)
 	for _, namespace := range filterOpts.Namespaces(clientset) {
-		diff, err := processNamespaceHpas(clientset, namespace, filterOpts)
+		diff, duplicates, err := processNamespaceHpas(clientset, dynamicClient, namespace, filterOpts)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
 			continue
@@ -98,8 +249,14 @@ func GetUnusedHpas(filterOpts *filters.Options, clientset kubernetes.Interface,
 		case "namespace":
 			resources[namespace] = make(map[string][]ResourceInfo)
 			resources[namespace]["Hpa"] = diff
+			if len(duplicates) > 0 {
+				resources[namespace]["DuplicateHpa"] = duplicates
+			}
 		case "resource":
 			appendResources(resources, "Hpa", namespace, diff)
+			if len(duplicates) > 0 {
+				appendResources(resources, "DuplicateHpa", namespace, duplicates)
+			}
 		}
 	}
 
@@ -5,19 +5,34 @@ import (
 	"encoding/json"
 	"reflect"
 	"testing"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/dynamic"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/kubernetes/scheme"
+	k8stesting "k8s.io/client-go/testing"
 
 	"github.com/yonahd/kor/pkg/common"
 	"github.com/yonahd/kor/pkg/filters"
 )
 
-func createTestHpas(t *testing.T) *fake.Clientset {
+func createTestScaleTarget(kind, apiVersion, name string, replicas int64) *unstructured.Unstructured {
+	target := CreateTestUnstructered(kind, apiVersion, testNamespace, name)
+	_ = unstructured.SetNestedField(target.Object, replicas, "spec", "replicas")
+	return target
+}
+
+func createTestHpas(t *testing.T) (*fake.Clientset, dynamic.Interface) {
 	clientset := fake.NewSimpleClientset()
 
 	_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), &corev1.Namespace{
@@ -28,61 +43,149 @@ func createTestHpas(t *testing.T) *fake.Clientset {
 		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
 	}
 
-	deploymentName := "test-deployment"
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*v1.APIResourceList{
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []v1.APIResource{
+				{Name: "deployments", Kind: "Deployment", Namespaced: true},
+				{Name: "deployments/scale", Kind: "Scale", Namespaced: true},
+			},
+		},
+		{
+			GroupVersion: "argoproj.io/v1alpha1",
+			APIResources: []v1.APIResource{
+				{Name: "rollouts", Kind: "Rollout", Namespaced: true},
+				{Name: "rollouts/scale", Kind: "Scale", Namespaced: true},
+			},
+		},
+		{
+			GroupVersion: "example.com/v1",
+			APIResources: []v1.APIResource{
+				{Name: "widgets", Kind: "Widget", Namespaced: true},
+			},
+		},
+	}
 
-	deployment1 := CreateTestDeployment(testNamespace, deploymentName, 1, AppLabels)
+	deploymentName := "test-deployment"
 
-	_, err = clientset.AppsV1().Deployments(testNamespace).Create(context.TODO(), deployment1, v1.CreateOptions{})
-	if err != nil {
-		t.Fatalf("Error creating fake deployment: %v", err)
+	dynamicClient := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			{Group: "apps", Version: "v1", Resource: "deployments"}:           "DeploymentList",
+			{Group: "argoproj.io", Version: "v1alpha1", Resource: "rollouts"}: "RolloutList",
+		},
+		createTestScaleTarget("Deployment", "apps/v1", deploymentName, 1),
+		createTestScaleTarget("Deployment", "apps/v1", "scaled-down", 0),
+		createTestScaleTarget("Rollout", "argoproj.io/v1alpha1", "web", 3),
+		createTestScaleTarget("Rollout", "argoproj.io/v1alpha1", "api", 2),
+	)
+
+	rolloutTarget := func(name string) autoscalingv2.CrossVersionObjectReference {
+		return autoscalingv2.CrossVersionObjectReference{APIVersion: "argoproj.io/v1alpha1", Kind: "Rollout", Name: name}
 	}
 
 	hpa1 := CreateTestHpa(testNamespace, "test-hpa1", deploymentName, 1, 1, AppLabels)
-	_, err = clientset.AutoscalingV2().HorizontalPodAutoscalers(testNamespace).Create(context.TODO(), hpa1, v1.CreateOptions{})
-	if err != nil {
-		t.Fatalf("Error creating fake Hpa: %v", err)
-	}
-
+	hpa1.CreationTimestamp = v1.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
 	hpa2 := CreateTestHpa(testNamespace, "test-hpa2", "non-existing-deployment", 1, 1, AppLabels)
-	_, err = clientset.AutoscalingV2().HorizontalPodAutoscalers(testNamespace).Create(context.TODO(), hpa2, v1.CreateOptions{})
-	if err != nil {
-		t.Fatalf("Error creating fake Hpa: %v", err)
-	}
-
 	hpa3 := CreateTestHpa(testNamespace, "test-hpa3", deploymentName, 1, 1, UsedLabels)
-	_, err = clientset.AutoscalingV2().HorizontalPodAutoscalers(testNamespace).Create(context.TODO(), hpa3, v1.CreateOptions{})
-	if err != nil {
-		t.Fatalf("Error creating fake Hpa: %v", err)
+	hpa4 := CreateTestHpa(testNamespace, "test-hpa4", "non-existing-deployment", 1, 1, UnusedLabels)
+
+	hpa5 := CreateTestHpa(testNamespace, "test-hpa5", "", 1, 1, AppLabels)
+	hpa5.Spec.ScaleTargetRef = rolloutTarget("web")
+
+	hpa6 := CreateTestHpa(testNamespace, "test-hpa6", "scaled-down", 1, 1, AppLabels)
+
+	// Same target as test-hpa1 but older, so test-hpa7 is the one kept
+	hpa7 := CreateTestHpa(testNamespace, "test-hpa7", deploymentName, 1, 1, AppLabels)
+	hpa7.CreationTimestamp = v1.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	hpa8 := CreateTestHpa(testNamespace, "test-hpa8", "", 1, 1, AppLabels)
+	hpa8.Spec.ScaleTargetRef = autoscalingv2.CrossVersionObjectReference{APIVersion: "example.com/v1", Kind: "Widget", Name: "widget"}
+
+	hpa9 := CreateTestHpa(testNamespace, "test-hpa9", "", 1, 1, AppLabels)
+	hpa9.Spec.ScaleTargetRef = rolloutTarget("api")
+	hpa9.Status.Conditions = []autoscalingv2.HorizontalPodAutoscalerCondition{
+		{
+			Type:               autoscalingv2.ScalingActive,
+			Status:             corev1.ConditionFalse,
+			Reason:             "FailedGetResourceMetric",
+			LastTransitionTime: v1.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
 	}
 
-	hpa4 := CreateTestHpa(testNamespace, "test-hpa4", "non-existing-deployment", 1, 1, UnusedLabels)
-	_, err = clientset.AutoscalingV2().HorizontalPodAutoscalers(testNamespace).Create(context.TODO(), hpa4, v1.CreateOptions{})
-	if err != nil {
-		t.Fatalf("Error creating fake Hpa: %v", err)
+	// Scale targets without apiVersion are defaulted for built-in kinds only
+	hpa10 := CreateTestHpa(testNamespace, "test-hpa10", "", 1, 1, AppLabels)
+	hpa10.Spec.ScaleTargetRef = autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "legacy-deployment"}
+
+	hpa11 := CreateTestHpa(testNamespace, "test-hpa11", "", 1, 1, AppLabels)
+	hpa11.Spec.ScaleTargetRef = autoscalingv2.CrossVersionObjectReference{Kind: "Widget", Name: "widget"}
+
+	// Same target as test-hpa7 once the apiVersion is defaulted
+	hpa12 := CreateTestHpa(testNamespace, "test-hpa12", "", 1, 1, AppLabels)
+	hpa12.Spec.ScaleTargetRef = autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: deploymentName}
+	hpa12.CreationTimestamp = v1.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, hpa := range []*autoscalingv2.HorizontalPodAutoscaler{hpa1, hpa2, hpa3, hpa4, hpa5, hpa6, hpa7, hpa8, hpa9, hpa10, hpa11, hpa12} {
+		_, err = clientset.AutoscalingV2().HorizontalPodAutoscalers(testNamespace).Create(context.TODO(), hpa, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake Hpa: %v", err)
+		}
 	}
 
-	return clientset
+	return clientset, dynamicClient
 }
 
 func TestExtractUnusedHpas(t *testing.T) {
-	clientset := createTestHpas(t)
+	clientset, dynamicClient := createTestHpas(t)
 
-	unusedHpas, err := processNamespaceHpas(clientset, testNamespace, &filters.Options{})
+	unusedHpas, duplicateHpas, err := processNamespaceHpas(clientset, dynamicClient, testNamespace, filters.NewFilterOptions())
 	if err != nil {
-		t.Errorf("Expected no error, got %v", err)
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expectedHpas := []ResourceInfo{
+		{Name: "test-hpa10", Reason: "Scale target Deployment legacy-deployment does not exist"},
+		{Name: "test-hpa11", Reason: "Scale target Widget widget has no apiVersion and is unresolvable"},
+		{Name: "test-hpa2", Reason: "Scale target Deployment non-existing-deployment does not exist"},
+		{Name: "test-hpa4", Reason: "Marked with unused label"},
+		{Name: "test-hpa6", Reason: "Scale target Deployment scaled-down is scaled to zero, autoscaling is disabled"},
+		{Name: "test-hpa8", Reason: "Scale target kind Widget has no scale subresource"},
+		{Name: "test-hpa9", Reason: "HPA metrics have been failing since 2024-01-01T00:00:00Z (FailedGetResourceMetric)"},
 	}
 
-	if len(unusedHpas) != 2 {
-		t.Errorf("Expected 1 unused HPA, got %d", len(unusedHpas))
+	if !reflect.DeepEqual(unusedHpas, expectedHpas) {
+		t.Errorf("Expected %v, got %v", expectedHpas, unusedHpas)
 	}
 
-	if unusedHpas[0].Name != "test-hpa2" && unusedHpas[1].Name != "test-hpa4" {
-		t.Errorf("Expected 'test-hpa2', 'test-hpa4', got %s, %s", unusedHpas[0], unusedHpas[1])
+	expectedDuplicates := []ResourceInfo{
+		{Name: "test-hpa1", Reason: "Scale target Deployment test-deployment is also targeted by HPA test-hpa7"},
+		{Name: "test-hpa12", Reason: "Scale target Deployment test-deployment is also targeted by HPA test-hpa7"},
+	}
+
+	if !reflect.DeepEqual(duplicateHpas, expectedDuplicates) {
+		t.Errorf("Expected %v, got %v", expectedDuplicates, duplicateHpas)
+	}
+}
+
+func TestExtractUnusedHpasForbiddenTarget(t *testing.T) {
+	clientset, dynamicClient := createTestHpas(t)
+
+	dynamicClient.(*fakedynamic.FakeDynamicClient).PrependReactor("get", "rollouts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.NewForbidden(schema.GroupResource{Group: "argoproj.io", Resource: "rollouts"}, "api", nil)
+	})
+
+	unusedHpas, _, err := processNamespaceHpas(clientset, dynamicClient, testNamespace, filters.NewFilterOptions())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// test-hpa9 targets a Rollout kor may not read, the other HPAs are still checked
+	if resourceInfoContains(unusedHpas, "test-hpa9") || !resourceInfoContains(unusedHpas, "test-hpa2") {
+		t.Errorf("Expected only the forbidden scale target to be skipped, got %v", unusedHpas)
 	}
 }
 
 func TestGetUnusedHpasStructured(t *testing.T) {
-	clientset := createTestHpas(t)
+	clientset, dynamicClient := createTestHpas(t)
 
 	opts := common.Opts{
 		WebhookURL:    "",
@@ -93,7 +196,7 @@ func TestGetUnusedHpasStructured(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedHpas(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedHpas(&filters.Options{}, clientset, dynamicClient, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedHpasStructured: %v", err)
 	}
@@ -101,8 +204,16 @@ func TestGetUnusedHpasStructured(t *testing.T) {
 	expectedOutput := map[string]map[string][]string{
 		testNamespace: {
 			"Hpa": {
+				"test-hpa10",
+				"test-hpa11",
 				"test-hpa2",
 				"test-hpa4",
+				"test-hpa6",
+				"test-hpa8",
+			},
+			"DuplicateHpa": {
+				"test-hpa1",
+				"test-hpa12",
 			},
 		},
 	}
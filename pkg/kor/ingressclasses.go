@@ -0,0 +1,147 @@
+package kor
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+//go:embed exceptions/ingressclasses/ingressclasses.json
+var ingressClassesConfig []byte
+
+// Annotation used to select an ingress controller before spec.ingressClassName existed
+const legacyIngressClassAnnotation = "kubernetes.io/ingress.class"
+
+// Find the IngressClasses referenced by Ingresses, and whether some Ingress relies on the default class
+func retrieveUsedIngressClasses(clientset kubernetes.Interface) ([]string, bool, error) {
+	ingresses, err := clientset.NetworkingV1().Ingresses("").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, false, err
+	}
+
+	var usedIngressClasses []string
+	usesDefault := false
+	for _, ingress := range ingresses.Items {
+		switch {
+		case ingress.Spec.IngressClassName != nil:
+			usedIngressClasses = append(usedIngressClasses, *ingress.Spec.IngressClassName)
+		case ingress.Annotations[legacyIngressClassAnnotation] != "":
+			usedIngressClasses = append(usedIngressClasses, ingress.Annotations[legacyIngressClassAnnotation])
+		default:
+			usesDefault = true
+		}
+	}
+
+	return RemoveDuplicatesAndSort(usedIngressClasses), usesDefault, nil
+}
+
+func processIngressClasses(clientset kubernetes.Interface, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	ics, err := clientset.NetworkingV1().IngressClasses().List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+	if err != nil {
+		return nil, err
+	}
+
+	usedIngressClasses, usesDefault, err := retrieveUsedIngressClasses(clientset)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := unmarshalConfig(ingressClassesConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var unusedIngressClasses []ResourceInfo
+	ingressClassNames := make([]string, 0, len(ics.Items))
+	defaultClasses := make(map[string]bool)
+
+	for _, ic := range ics.Items {
+		if pass, _ := filter.SetObject(&ic).Run(filterOpts); pass {
+			continue
+		}
+
+		if ic.Labels["kor/used"] == "false" {
+			unusedIngressClasses = append(unusedIngressClasses, ResourceInfo{Name: ic.Name, Reason: "Marked with unused label"})
+			continue
+		}
+
+		exceptionFound, err := isResourceException(ic.Name, ic.Namespace, config.ExceptionIngressClasses)
+		if err != nil {
+			return nil, err
+		}
+
+		if exceptionFound {
+			continue
+		}
+
+		// Ingresses without a class are handled by the default class
+		if ic.Annotations[networkingv1.AnnotationIsDefaultIngressClass] == "true" {
+			if usesDefault {
+				continue
+			}
+			defaultClasses[ic.Name] = true
+		}
+
+		ingressClassNames = append(ingressClassNames, ic.Name)
+	}
+
+	diff := CalculateResourceDifference(usedIngressClasses, ingressClassNames)
+	for _, name := range diff {
+		reason := "IngressClass is not used by any Ingress"
+		if defaultClasses[name] {
+			reason = "IngressClass is the default class but no Ingress uses it"
+		}
+		unusedIngressClasses = append(unusedIngressClasses, ResourceInfo{Name: name, Reason: reason})
+	}
+	return unusedIngressClasses, nil
+}
+
+func GetUnusedIngressClasses(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	resources := make(map[string]map[string][]ResourceInfo)
+	diff, err := processIngressClasses(clientset, filterOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to process ingressClasses: %v\n", err)
+	}
+	if opts.DeleteFlag {
+		if diff, err = DeleteResource(diff, clientset, "", "IngressClass", opts.NoInteractive); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to delete IngressClass %s: %v\n", diff, err)
+		}
+	}
+	switch opts.GroupBy {
+	case "namespace":
+		resources[""] = make(map[string][]ResourceInfo)
+		resources[""]["IngressClass"] = diff
+	case "resource":
+		appendResources(resources, "IngressClass", "", diff)
+	}
+
+	var outputBuffer bytes.Buffer
+	var jsonResponse []byte
+	switch outputFormat {
+	case "table":
+		outputBuffer = FormatOutput(resources, opts)
+	case "json", "yaml":
+		var err error
+		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
+			return "", err
+		}
+	}
+
+	unusedIngressClasses, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
+	if err != nil {
+		fmt.Printf("err: %v\n", err)
+	}
+
+	return unusedIngressClasses, nil
+}
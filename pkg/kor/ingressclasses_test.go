@@ -0,0 +1,123 @@
+package kor
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func createTestIngressClasses(t *testing.T, withUnclassifiedIngress bool) *fake.Clientset {
+	clientset := fake.NewSimpleClientset()
+
+	ingressClasses := []*networkingv1.IngressClass{
+		CreateTestIngressClass("nginx", false, AppLabels),
+		CreateTestIngressClass("legacy", false, AppLabels),
+		CreateTestIngressClass("default", true, AppLabels),
+		CreateTestIngressClass("uninstalled", false, AppLabels),
+		CreateTestIngressClass("marked", false, UnusedLabels),
+	}
+
+	for _, ingressClass := range ingressClasses {
+		_, err := clientset.NetworkingV1().IngressClasses().Create(context.TODO(), ingressClass, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake %s: %v", "IngressClass", err)
+		}
+	}
+
+	className := "nginx"
+	ingress1 := CreateTestIngress(testNamespace, "ingress-1", "my-service", "", AppLabels)
+	ingress1.Spec.IngressClassName = &className
+
+	ingress2 := CreateTestIngress(testNamespace, "ingress-2", "my-service", "", AppLabels)
+	ingress2.Annotations = map[string]string{legacyIngressClassAnnotation: "legacy"}
+
+	ingresses := []*networkingv1.Ingress{ingress1, ingress2}
+	if withUnclassifiedIngress {
+		ingresses = append(ingresses, CreateTestIngress(testNamespace, "ingress-3", "my-service", "", AppLabels))
+	}
+
+	for _, ingress := range ingresses {
+		_, err := clientset.NetworkingV1().Ingresses(testNamespace).Create(context.TODO(), ingress, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake %s: %v", "Ingress", err)
+		}
+	}
+
+	return clientset
+}
+
+func TestProcessIngressClasses(t *testing.T) {
+	clientset := createTestIngressClasses(t, false)
+
+	unusedIngressClasses, err := processIngressClasses(clientset, &filters.Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []ResourceInfo{
+		{Name: "marked", Reason: "Marked with unused label"},
+		{Name: "default", Reason: "IngressClass is the default class but no Ingress uses it"},
+		{Name: "uninstalled", Reason: "IngressClass is not used by any Ingress"},
+	}
+	if !reflect.DeepEqual(unusedIngressClasses, expected) {
+		t.Errorf("Expected %v, got %v", expected, unusedIngressClasses)
+	}
+}
+
+func TestProcessIngressClassesDefaultClass(t *testing.T) {
+	clientset := createTestIngressClasses(t, true)
+
+	unusedIngressClasses, err := processIngressClasses(clientset, &filters.Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []ResourceInfo{
+		{Name: "marked", Reason: "Marked with unused label"},
+		{Name: "uninstalled", Reason: "IngressClass is not used by any Ingress"},
+	}
+	if !reflect.DeepEqual(unusedIngressClasses, expected) {
+		t.Errorf("Expected %v, got %v", expected, unusedIngressClasses)
+	}
+}
+
+func TestGetUnusedIngressClassesStructured(t *testing.T) {
+	clientset := createTestIngressClasses(t, true)
+
+	opts := common.Opts{
+		WebhookURL:    "",
+		Channel:       "",
+		Token:         "",
+		DeleteFlag:    false,
+		NoInteractive: true,
+		GroupBy:       "namespace",
+	}
+
+	output, err := GetUnusedIngressClasses(&filters.Options{}, clientset, "json", opts)
+	if err != nil {
+		t.Fatalf("Error calling GetUnusedIngressClasses: %v", err)
+	}
+
+	expectedOutput := map[string]map[string][]string{
+		"": {
+			"IngressClass": {"marked", "uninstalled"},
+		},
+	}
+
+	var actualOutput map[string]map[string][]string
+	if err := json.Unmarshal([]byte(output), &actualOutput); err != nil {
+		t.Fatalf("Error unmarshaling actual output: %v", err)
+	}
+
+	if !reflect.DeepEqual(expectedOutput, actualOutput) {
+		t.Errorf("Expected output does not match actual output")
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	batchv1 "k8s.io/api/batch/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/yonahd/kor/pkg/common"
@@ -20,6 +21,16 @@ import (
 //go:embed exceptions/jobs/jobs.json
 var jobsConfig []byte
 
+// Return the name of the existing CronJob that created the Job, if any
+func retrieveOwnerCronJob(job batchv1.Job, cronJobNames map[types.UID]string) string {
+	for _, owner := range job.OwnerReferences {
+		if owner.Kind == "CronJob" {
+			return cronJobNames[owner.UID]
+		}
+	}
+	return ""
+}
+
 func processNamespaceJobs(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
 	jobsList, err := clientset.BatchV1().Jobs(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
 	if err != nil {
@@ -31,6 +42,16 @@ func processNamespaceJobs(clientset kubernetes.Interface, namespace string, filt
 		return nil, err
 	}
 
+	cronJobs, err := clientset.BatchV1().CronJobs(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	cronJobNames := make(map[types.UID]string, len(cronJobs.Items))
+	for _, cronJob := range cronJobs.Items {
+		cronJobNames[cronJob.UID] = cronJob.Name
+	}
+
 	var unusedJobNames []ResourceInfo
 
 	for _, job := range jobsList.Items {
@@ -50,6 +71,9 @@ func processNamespaceJobs(clientset kubernetes.Interface, namespace string, filt
 		// if the job has completionTime and succeeded count greater than zero, think the job is completed
 		if job.Status.CompletionTime != nil && job.Status.Succeeded > 0 {
 			reason := "Job has completed"
+			if cronJobName := retrieveOwnerCronJob(job, cronJobNames); cronJobName != "" {
+				reason = fmt.Sprintf("Job has completed and is history of CronJob %s, successfulJobsHistoryLimit will clean it up", cronJobName)
+			}
 			unusedJobNames = append(unusedJobNames, ResourceInfo{Name: job.Name, Reason: reason})
 			continue
 		} else {
@@ -1,19 +1,26 @@
 package kor
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strings"
 
+	corev1 "k8s.io/api/core/v1"
 	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/util/homedir"
+	"k8s.io/utils/strings/slices"
 )
 
 type ExceptionResource struct {
@@ -27,18 +34,24 @@ type IncludeExcludeLists struct {
 }
 
 type Config struct {
-	ExceptionClusterRoles    []ExceptionResource `json:"exceptionClusterRoles"`
-	ExceptionConfigMaps      []ExceptionResource `json:"exceptionConfigMaps"`
-	ExceptionCrds            []ExceptionResource `json:"exceptionCrds"`
-	ExceptionDaemonSets      []ExceptionResource `json:"exceptionDaemonSets"`
-	ExceptionRoles           []ExceptionResource `json:"exceptionRoles"`
-	ExceptionSecrets         []ExceptionResource `json:"exceptionSecrets"`
-	ExceptionServiceAccounts []ExceptionResource `json:"exceptionServiceAccounts"`
-	ExceptionServices        []ExceptionResource `json:"exceptionServices"`
-	ExceptionStorageClasses  []ExceptionResource `json:"exceptionStorageClasses"`
-	ExceptionJobs            []ExceptionResource `json:"exceptionJobs"`
-	ExceptionPdbs            []ExceptionResource `json:"exceptionPdbs"`
-	ExceptionRoleBindings    []ExceptionResource `json:"exceptionRoleBindings"`
+	ExceptionClusterRoles        []ExceptionResource `json:"exceptionClusterRoles"`
+	ExceptionClusterRoleBindings []ExceptionResource `json:"exceptionClusterRoleBindings"`
+	ExceptionConfigMaps          []ExceptionResource `json:"exceptionConfigMaps"`
+	ExceptionCronJobs            []ExceptionResource `json:"exceptionCronJobs"`
+	ExceptionCrds                []ExceptionResource `json:"exceptionCrds"`
+	ExceptionDaemonSets          []ExceptionResource `json:"exceptionDaemonSets"`
+	ExceptionNamespaces          []ExceptionResource `json:"exceptionNamespaces"`
+	ExceptionRoles               []ExceptionResource `json:"exceptionRoles"`
+	ExceptionSecrets             []ExceptionResource `json:"exceptionSecrets"`
+	ExceptionServiceAccounts     []ExceptionResource `json:"exceptionServiceAccounts"`
+	ExceptionServices            []ExceptionResource `json:"exceptionServices"`
+	ExceptionStorageClasses      []ExceptionResource `json:"exceptionStorageClasses"`
+	ExceptionJobs                []ExceptionResource `json:"exceptionJobs"`
+	ExceptionPdbs                []ExceptionResource `json:"exceptionPdbs"`
+	ExceptionPriorityClasses     []ExceptionResource `json:"exceptionPriorityClasses"`
+	ExceptionRoleBindings        []ExceptionResource `json:"exceptionRoleBindings"`
+	ExceptionIngressClasses      []ExceptionResource `json:"exceptionIngressClasses"`
+	ExceptionRuntimeClasses      []ExceptionResource `json:"exceptionRuntimeClasses"`
 	// Add other configurations if needed
 }
 
@@ -205,3 +218,182 @@ func convertNamesToPresenseMap(names []string, _ []string, err error) (map[strin
 
 	return namesMap, nil
 }
+
+// Call visit with the spec of every Pod and every workload template in the cluster
+func walkPodSpecs(clientset kubernetes.Interface, visit func(spec corev1.PodSpec)) error {
+	pods, err := clientset.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, pod := range pods.Items {
+		visit(pod.Spec)
+	}
+
+	// Workload templates keep their references in use even while they run no pods
+	deployments, err := clientset.AppsV1().Deployments("").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, deployment := range deployments.Items {
+		visit(deployment.Spec.Template.Spec)
+	}
+
+	statefulSets, err := clientset.AppsV1().StatefulSets("").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, statefulSet := range statefulSets.Items {
+		visit(statefulSet.Spec.Template.Spec)
+	}
+
+	daemonSets, err := clientset.AppsV1().DaemonSets("").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, daemonSet := range daemonSets.Items {
+		visit(daemonSet.Spec.Template.Spec)
+	}
+
+	replicaSets, err := clientset.AppsV1().ReplicaSets("").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, replicaSet := range replicaSets.Items {
+		visit(replicaSet.Spec.Template.Spec)
+	}
+
+	jobs, err := clientset.BatchV1().Jobs("").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs.Items {
+		visit(job.Spec.Template.Spec)
+	}
+
+	cronJobs, err := clientset.BatchV1().CronJobs("").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, cronJob := range cronJobs.Items {
+		visit(cronJob.Spec.JobTemplate.Spec.Template.Spec)
+	}
+
+	return nil
+}
+
+// Report whether the namespace runs any Pod or holds any workload that can create one
+func namespaceHasWorkloads(clientset kubernetes.Interface, namespace string) (bool, error) {
+	// A single item is enough to tell the namespace is in use
+	listOptions := metav1.ListOptions{Limit: 1}
+
+	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), listOptions)
+	if err != nil || len(pods.Items) > 0 {
+		return err == nil, err
+	}
+
+	deployments, err := clientset.AppsV1().Deployments(namespace).List(context.TODO(), listOptions)
+	if err != nil || len(deployments.Items) > 0 {
+		return err == nil, err
+	}
+
+	statefulSets, err := clientset.AppsV1().StatefulSets(namespace).List(context.TODO(), listOptions)
+	if err != nil || len(statefulSets.Items) > 0 {
+		return err == nil, err
+	}
+
+	daemonSets, err := clientset.AppsV1().DaemonSets(namespace).List(context.TODO(), listOptions)
+	if err != nil || len(daemonSets.Items) > 0 {
+		return err == nil, err
+	}
+
+	replicaSets, err := clientset.AppsV1().ReplicaSets(namespace).List(context.TODO(), listOptions)
+	if err != nil || len(replicaSets.Items) > 0 {
+		return err == nil, err
+	}
+
+	replicationControllers, err := clientset.CoreV1().ReplicationControllers(namespace).List(context.TODO(), listOptions)
+	if err != nil || len(replicationControllers.Items) > 0 {
+		return err == nil, err
+	}
+
+	jobs, err := clientset.BatchV1().Jobs(namespace).List(context.TODO(), listOptions)
+	if err != nil || len(jobs.Items) > 0 {
+		return err == nil, err
+	}
+
+	cronJobs, err := clientset.BatchV1().CronJobs(namespace).List(context.TODO(), listOptions)
+	if err != nil || len(cronJobs.Items) > 0 {
+		return err == nil, err
+	}
+
+	return false, nil
+}
+
+// Walk every listable resource type returned by discovery and call visit for each listed object
+// The kinds that could not be listed are returned, their objects are unknown rather than missing
+func walkDiscoveredResources(resourceTypes []*metav1.APIResourceList, dynamicClient dynamic.Interface, listOptions metav1.ListOptions, visit func(gvr schema.GroupVersionResource, resourceType metav1.APIResource, item unstructured.Unstructured)) ([]schema.GroupKind, error) {
+	var failedKinds []schema.GroupKind
+	for _, apiResourceList := range resourceTypes {
+		gv, err := schema.ParseGroupVersion(apiResourceList.GroupVersion)
+		if err != nil {
+			return failedKinds, err
+		}
+
+		for _, resourceType := range apiResourceList.APIResources {
+			if !slices.Contains(resourceType.Verbs, "list") || strings.Contains(resourceType.Name, "/") {
+				continue
+			}
+
+			gvr := gv.WithResource(resourceType.Name)
+			resourceList, err := dynamicClient.
+				Resource(gvr).
+				Namespace(metav1.NamespaceAll).
+				List(context.TODO(), listOptions)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error listing resources for GVR %s: %v\n", gvr.String(), err)
+				failedKinds = append(failedKinds, gv.WithKind(resourceType.Kind).GroupKind())
+				continue
+			}
+
+			for _, item := range resourceList.Items {
+				if item.GetKind() == "" {
+					item.SetKind(resourceType.Kind)
+				}
+				visit(gvr, resourceType, item)
+			}
+		}
+	}
+	return failedKinds, nil
+}
+
+const (
+	severityMedium = iota + 1
+	severityHigh
+	severityCritical
+)
+
+var severityNames = map[int]string{
+	severityMedium:   "medium",
+	severityHigh:     "high",
+	severityCritical: "critical",
+}
+
+// severityFinding is a reported resource ranked by how much damage it can cause
+type severityFinding struct {
+	severity int
+	info     ResourceInfo
+}
+
+func sortFindingsBySeverity(findings []severityFinding) []ResourceInfo {
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].severity != findings[j].severity {
+			return findings[i].severity > findings[j].severity
+		}
+		return findings[i].info.Name < findings[j].info.Name
+	})
+	sorted := make([]ResourceInfo, 0, len(findings))
+	for _, finding := range findings {
+		sorted = append(sorted, finding.info)
+	}
+	return sorted
+}
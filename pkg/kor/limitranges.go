@@ -0,0 +1,98 @@
+package kor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func processNamespaceLimitRanges(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	limitRanges, err := clientset.CoreV1().LimitRanges(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+	if err != nil {
+		return nil, err
+	}
+	if len(limitRanges.Items) == 0 {
+		return nil, nil
+	}
+
+	// LimitRanges only apply to the Pods and PVCs created in their namespace
+	hasWorkloads, err := namespaceHasWorkloads(clientset, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var unusedLimitRanges []ResourceInfo
+
+	for _, limitRange := range limitRanges.Items {
+		if pass, _ := filter.SetObject(&limitRange).Run(filterOpts); pass {
+			continue
+		}
+
+		if limitRange.Labels["kor/used"] == "false" {
+			unusedLimitRanges = append(unusedLimitRanges, ResourceInfo{Name: limitRange.Name, Reason: "Marked with unused label"})
+			continue
+		}
+
+		if !hasWorkloads {
+			unusedLimitRanges = append(unusedLimitRanges, ResourceInfo{Name: limitRange.Name, Reason: "LimitRange is in a namespace with no workloads or pods"})
+		}
+	}
+
+	return unusedLimitRanges, nil
+}
+
+func GetUnusedLimitRanges(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	resources := make(map[string]map[string][]ResourceInfo)
+
+	for _, namespace := range filterOpts.Namespaces(clientset) {
+		diff, err := processNamespaceLimitRanges(clientset, namespace, filterOpts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
+			continue
+		}
+		if opts.DeleteFlag {
+			if diff, err = DeleteResource(diff, clientset, namespace, "LimitRange", opts.NoInteractive); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to delete LimitRange %s in namespace %s: %v\n", diff, namespace, err)
+			}
+		}
+		switch opts.GroupBy {
+		case "namespace":
+			if diff != nil {
+				resources[namespace] = make(map[string][]ResourceInfo)
+				resources[namespace]["LimitRange"] = diff
+			}
+		case "resource":
+			if diff != nil {
+				appendResources(resources, "LimitRange", namespace, diff)
+			}
+		}
+	}
+
+	var outputBuffer bytes.Buffer
+	var jsonResponse []byte
+	switch outputFormat {
+	case "table":
+		outputBuffer = FormatOutput(resources, opts)
+	case "json", "yaml":
+		var err error
+		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
+			return "", err
+		}
+	}
+
+	unusedLimitRanges, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
+	if err != nil {
+		fmt.Printf("err: %v\n", err)
+	}
+
+	return unusedLimitRanges, nil
+}
@@ -0,0 +1,114 @@
+package kor
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func createTestLimitRanges(t *testing.T) *fake.Clientset {
+	clientset := fake.NewSimpleClientset()
+
+	for _, ns := range []string{testNamespace, emptyNamespace} {
+		_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), &corev1.Namespace{
+			ObjectMeta: v1.ObjectMeta{Name: ns},
+		}, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating namespace %s: %v", ns, err)
+		}
+	}
+
+	// A workload scaled to zero still uses the LimitRange when it scales back up
+	deployment := CreateTestDeployment(testNamespace, "scaled-down", 0, AppLabels)
+	_, err := clientset.AppsV1().Deployments(testNamespace).Create(context.TODO(), deployment, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake %s: %v", "Deployment", err)
+	}
+
+	limitRanges := []*corev1.LimitRange{
+		CreateTestLimitRange(testNamespace, "defaults", AppLabels),
+		CreateTestLimitRange(testNamespace, "marked", UnusedLabels),
+		CreateTestLimitRange(emptyNamespace, "template-limits", AppLabels),
+	}
+
+	for _, limitRange := range limitRanges {
+		_, err := clientset.CoreV1().LimitRanges(limitRange.Namespace).Create(context.TODO(), limitRange, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake %s: %v", "LimitRange", err)
+		}
+	}
+
+	return clientset
+}
+
+func TestProcessNamespaceLimitRanges(t *testing.T) {
+	clientset := createTestLimitRanges(t)
+
+	unusedLimitRanges, err := processNamespaceLimitRanges(clientset, testNamespace, &filters.Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []ResourceInfo{
+		{Name: "marked", Reason: "Marked with unused label"},
+	}
+	if !reflect.DeepEqual(unusedLimitRanges, expected) {
+		t.Errorf("Expected %v, got %v", expected, unusedLimitRanges)
+	}
+
+	unusedLimitRanges, err = processNamespaceLimitRanges(clientset, emptyNamespace, &filters.Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected = []ResourceInfo{
+		{Name: "template-limits", Reason: "LimitRange is in a namespace with no workloads or pods"},
+	}
+	if !reflect.DeepEqual(unusedLimitRanges, expected) {
+		t.Errorf("Expected %v, got %v", expected, unusedLimitRanges)
+	}
+}
+
+func TestGetUnusedLimitRangesStructured(t *testing.T) {
+	clientset := createTestLimitRanges(t)
+
+	opts := common.Opts{
+		WebhookURL:    "",
+		Channel:       "",
+		Token:         "",
+		DeleteFlag:    false,
+		NoInteractive: true,
+		GroupBy:       "namespace",
+	}
+
+	output, err := GetUnusedLimitRanges(&filters.Options{}, clientset, "json", opts)
+	if err != nil {
+		t.Fatalf("Error calling GetUnusedLimitRanges: %v", err)
+	}
+
+	expectedOutput := map[string]map[string][]string{
+		testNamespace: {
+			"LimitRange": {"marked"},
+		},
+		emptyNamespace: {
+			"LimitRange": {"template-limits"},
+		},
+	}
+
+	var actualOutput map[string]map[string][]string
+	if err := json.Unmarshal([]byte(output), &actualOutput); err != nil {
+		t.Fatalf("Error unmarshaling actual output: %v", err)
+	}
+
+	if !reflect.DeepEqual(expectedOutput, actualOutput) {
+		t.Errorf("Expected output does not match actual output: %v", actualOutput)
+	}
+}
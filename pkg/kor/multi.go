@@ -34,10 +34,33 @@ func retrieveNoNamespaceDiff(clientset kubernetes.Interface, apiExtClient apiext
 			clusterRoleDiff := getUnusedClusterRoles(clientset, filterOpts)
 			noNamespaceDiff = append(noNamespaceDiff, clusterRoleDiff)
 			markedForRemoval[counter] = true
+		case "crb", "clusterrolebinding", "clusterrolebindings":
+			clusterRoleBindingDiff := getUnusedClusterRoleBindings(clientset, filterOpts)
+			noNamespaceDiff = append(noNamespaceDiff, clusterRoleBindingDiff)
+			markedForRemoval[counter] = true
 		case "sc", "storageclass", "storageclasses":
 			storageClassDiff := getUnusedStorageClasses(clientset, filterOpts)
 			noNamespaceDiff = append(noNamespaceDiff, storageClassDiff)
 			markedForRemoval[counter] = true
+		case "pc", "priorityclass", "priorityclasses":
+			priorityClassDiff := getUnusedPriorityClasses(clientset, filterOpts)
+			noNamespaceDiff = append(noNamespaceDiff, priorityClassDiff)
+			markedForRemoval[counter] = true
+		case "ingressclass", "ingressclasses":
+			ingressClassDiff := getUnusedIngressClasses(clientset, filterOpts)
+			noNamespaceDiff = append(noNamespaceDiff, ingressClassDiff)
+			markedForRemoval[counter] = true
+		case "runtimeclass", "runtimeclasses":
+			runtimeClassDiff := getUnusedRuntimeClasses(clientset, filterOpts)
+			noNamespaceDiff = append(noNamespaceDiff, runtimeClassDiff)
+			markedForRemoval[counter] = true
+		case "vs", "volumesnapshot", "volumesnapshots":
+			// VolumeSnapshots themselves are namespaced, so the resource stays in the list
+			noNamespaceDiff = append(noNamespaceDiff, getUnusedVolumeSnapshotClusterResources(clientset, dynamicClient, filterOpts)...)
+		case "ns", "namespace", "namespaces":
+			namespaceDiff := getUnusedNamespaces(clientset, dynamicClient, filterOpts)
+			noNamespaceDiff = append(noNamespaceDiff, namespaceDiff)
+			markedForRemoval[counter] = true
 		}
 	}
 
@@ -52,7 +75,7 @@ func retrieveNoNamespaceDiff(clientset kubernetes.Interface, apiExtClient apiext
 	return noNamespaceDiff, clearedResourceList
 }
 
-func retrieveNamespaceDiffs(clientset kubernetes.Interface, namespace string, resourceList []string, filterOpts *filters.Options) []ResourceDiff {
+func retrieveNamespaceDiffs(clientset kubernetes.Interface, dynamicClient dynamic.Interface, namespace string, resourceList []string, filterOpts *filters.Options) []ResourceDiff {
 	var allDiffs []ResourceDiff
 	for _, resource := range resourceList {
 		var diffResult ResourceDiff
@@ -72,9 +95,11 @@ func retrieveNamespaceDiffs(clientset kubernetes.Interface, namespace string, re
 		case "role", "roles":
 			diffResult = getUnusedRoles(clientset, namespace, filterOpts)
 		case "hpa", "horizontalpodautoscaler", "horizontalpodautoscalers":
-			diffResult = getUnusedHpas(clientset, namespace, filterOpts)
+			diffResult = getUnusedHpas(clientset, dynamicClient, namespace, filterOpts)
 		case "pvc", "persistentvolumeclaim", "persistentvolumeclaims":
 			diffResult = getUnusedPvcs(clientset, namespace, filterOpts)
+		case "stspvc", "statefulsetpvc", "statefulsetpvcs":
+			diffResult = getUnusedStatefulSetPvcs(clientset, namespace, filterOpts)
 		case "ing", "ingress", "ingresses":
 			diffResult = getUnusedIngresses(clientset, namespace, filterOpts)
 		case "pdb", "poddisruptionbudget", "poddisruptionbudgets":
@@ -83,14 +108,22 @@ func retrieveNamespaceDiffs(clientset kubernetes.Interface, namespace string, re
 			diffResult = getUnusedPods(clientset, namespace, filterOpts)
 		case "job", "jobs":
 			diffResult = getUnusedJobs(clientset, namespace, filterOpts)
+		case "cj", "cronjob", "cronjobs":
+			diffResult = getUnusedCronJobs(clientset, namespace, filterOpts)
 		case "rs", "replicaset", "replicasets":
-			diffResult = getUnusedReplicaSets(clientset, namespace, filterOpts)
+			diffResult = getUnusedReplicaSets(clientset, dynamicClient, namespace, filterOpts)
 		case "ds", "daemonset", "daemonsets":
 			diffResult = getUnusedDaemonSets(clientset, namespace, filterOpts)
 		case "netpol", "networkpolicy", "networkpolicies":
 			diffResult = getUnusedNetworkPolicies(clientset, namespace, filterOpts)
 		case "rolebinding", "rolebindings":
 			diffResult = getUnusedNetworkPolicies(clientset, namespace, filterOpts)
+		case "quota", "resourcequota", "resourcequotas":
+			diffResult = getUnusedResourceQuotas(clientset, namespace, filterOpts)
+		case "limits", "limitrange", "limitranges":
+			diffResult = getUnusedLimitRanges(clientset, namespace, filterOpts)
+		case "vs", "volumesnapshot", "volumesnapshots":
+			diffResult = getUnusedVolumeSnapshots(clientset, dynamicClient, namespace, filterOpts)
 		default:
 			fmt.Printf("resource type %q is not supported\n", resource)
 		}
@@ -99,6 +132,13 @@ func retrieveNamespaceDiffs(clientset kubernetes.Interface, namespace string, re
 	return allDiffs
 }
 
+func deleteMultiResource(diff ResourceDiff, clientset kubernetes.Interface, dynamicClient dynamic.Interface, namespace string, noInteractive bool) ([]ResourceInfo, error) {
+	if gvr, ok := volumeSnapshotKindGVRs[diff.resourceType]; ok {
+		return DeleteDynamicResource(diff.diff, dynamicClient, namespace, gvr, diff.resourceType, noInteractive)
+	}
+	return DeleteResource(diff.diff, clientset, namespace, diff.resourceType, noInteractive)
+}
+
 func GetUnusedMulti(resourceNames string, filterOpts *filters.Options, clientset kubernetes.Interface, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts) (string, error) {
 	resourceList := strings.Split(resourceNames, ",")
 	namespaces := filterOpts.Namespaces(clientset)
@@ -114,7 +154,7 @@ func GetUnusedMulti(resourceNames string, filterOpts *filters.Options, clientset
 		for _, diff := range noNamespaceDiff {
 			if len(diff.diff) != 0 {
 				if opts.DeleteFlag {
-					if diff.diff, err = DeleteResource(diff.diff, clientset, "", diff.resourceType, opts.NoInteractive); err != nil {
+					if diff.diff, err = deleteMultiResource(diff, clientset, dynamicClient, "", opts.NoInteractive); err != nil {
 						fmt.Fprintf(os.Stderr, "Failed to delete %s %s: %v\n", diff.resourceType, diff.diff, err)
 					}
 				}
@@ -129,14 +169,14 @@ func GetUnusedMulti(resourceNames string, filterOpts *filters.Options, clientset
 	}
 
 	for _, namespace := range namespaces {
-		allDiffs := retrieveNamespaceDiffs(clientset, namespace, resourceList, filterOpts)
+		allDiffs := retrieveNamespaceDiffs(clientset, dynamicClient, namespace, resourceList, filterOpts)
 		if opts.GroupBy == "namespace" {
 			resources[namespace] = make(map[string][]ResourceInfo)
 		}
 
 		for _, diff := range allDiffs {
 			if opts.DeleteFlag {
-				if diff.diff, err = DeleteResource(diff.diff, clientset, namespace, diff.resourceType, opts.NoInteractive); err != nil {
+				if diff.diff, err = deleteMultiResource(diff, clientset, dynamicClient, namespace, opts.NoInteractive); err != nil {
 					fmt.Fprintf(os.Stderr, "Failed to delete %s %s in namespace %s: %v\n", diff.resourceType, diff.diff, namespace, err)
 				}
 			}
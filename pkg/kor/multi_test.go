@@ -8,6 +8,8 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/kubernetes/fake"
 
 	"github.com/yonahd/kor/pkg/common"
@@ -46,7 +48,7 @@ func TestRetrieveNamespaceDiff(t *testing.T) {
 	resourceList := []string{"cm", "pdb", "deployment"}
 	filterOpts := &filters.Options{}
 
-	namespaceDiff := retrieveNamespaceDiffs(clientset, testNamespace, resourceList, filterOpts)
+	namespaceDiff := retrieveNamespaceDiffs(clientset, fakedynamic.NewSimpleDynamicClient(runtime.NewScheme()), testNamespace, resourceList, filterOpts)
 
 	if len(namespaceDiff) != 3 {
 		t.Fatalf("Expected 3 diffs, got %d", len(namespaceDiff))
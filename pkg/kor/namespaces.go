@@ -0,0 +1,411 @@
+package kor
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/strings/slices"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+//go:embed exceptions/namespaces/namespaces.json
+var namespacesConfig []byte
+
+// Maximum number of remaining objects listed in the reason of a terminating namespace
+const maxRemainingObjects = 10
+
+// Detectors used to find the namespace content kor itself considers unused
+var namespaceContentDetectors = []func(kubernetes.Interface, string, *filters.Options) ResourceDiff{
+	getUnusedCMs,
+	getUnusedSVCs,
+	getUnusedSecrets,
+	getUnusedServiceAccounts,
+	getUnusedDeployments,
+	getUnusedStatefulSets,
+	getUnusedRoles,
+	getUnusedPvcs,
+	getUnusedStatefulSetPvcs,
+	getUnusedIngresses,
+	getUnusedPdbs,
+	getUnusedPods,
+	getUnusedJobs,
+	getUnusedCronJobs,
+	getUnusedDaemonSets,
+	getUnusedNetworkPolicies,
+	getUnusedRoleBindings,
+	getUnusedResourceQuotas,
+	getUnusedLimitRanges,
+}
+
+// Kinds of the resource types whose kor name differs from the object kind
+var resourceTypeKinds = map[string]string{
+	"Hpa":            "HorizontalPodAutoscaler",
+	"Pvc":            "PersistentVolumeClaim",
+	"StatefulSetPvc": "PersistentVolumeClaim",
+	"Pdb":            "PodDisruptionBudget",
+}
+
+// Reasons of the namespaces --delete removes, the other classes may still hold data such as PVCs, Secrets or custom resources
+const (
+	markedNamespaceReason       = "Marked with unused label"
+	onlyDefaultsNamespaceReason = "Namespace contains only default objects"
+)
+
+var workloadKinds = []string{"Pod", "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "ReplicationController", "Job", "CronJob"}
+
+func namespaceObjectKey(kind, name string) string {
+	return kind + "/" + name
+}
+
+// isDefaultNamespaceObject reports whether the object is created by the control plane in every namespace, Events are treated the same way
+func isDefaultNamespaceObject(object unstructured.Unstructured) bool {
+	switch object.GetKind() {
+	case "ConfigMap":
+		return object.GetName() == "kube-root-ca.crt"
+	case "ServiceAccount":
+		return object.GetName() == "default"
+	case "Secret":
+		return object.GetAnnotations()[corev1.ServiceAccountNameKey] == "default"
+	case "Event":
+		return true
+	}
+	return false
+}
+
+// Group every listable namespaced object of the cluster by its namespace, along with the kinds that could not be listed
+func retrieveNamespacesContent(resourceTypes []*metav1.APIResourceList, dynamicClient dynamic.Interface) (map[string][]unstructured.Unstructured, []schema.GroupKind, error) {
+	content := make(map[string][]unstructured.Unstructured)
+
+	failedKinds, err := walkDiscoveredResources(resourceTypes, dynamicClient, metav1.ListOptions{}, func(_ schema.GroupVersionResource, _ metav1.APIResource, item unstructured.Unstructured) {
+		content[item.GetNamespace()] = append(content[item.GetNamespace()], item)
+	})
+	return content, failedKinds, err
+}
+
+// Name the API groups a partial discovery left out
+func discoveryFailedGroups(err error) []string {
+	var groupErr *discovery.ErrGroupDiscoveryFailed
+	if !errors.As(err, &groupErr) {
+		return []string{"some API groups"}
+	}
+	groups := make([]string, 0, len(groupErr.Groups))
+	for gv := range groupErr.Groups {
+		groups = append(groups, gv.String())
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// Find the objects kor considers unused in each namespace, evaluated once per run with default options since user filters only select namespaces
+func retrieveUnusedNamespaceContent(clientset kubernetes.Interface, dynamicClient dynamic.Interface, namespaces []string) map[string]map[string]bool {
+	detectorOpts := filters.NewFilterOptions()
+	unusedContent := make(map[string]map[string]bool, len(namespaces))
+
+	for _, namespace := range namespaces {
+		diffs := make([]ResourceDiff, 0, len(namespaceContentDetectors)+2)
+		for _, detector := range namespaceContentDetectors {
+			diffs = append(diffs, detector(clientset, namespace, detectorOpts))
+		}
+		// HPA scale targets and ReplicaSet owners are resolved through the dynamic client
+		diffs = append(diffs, getUnusedHpas(clientset, dynamicClient, namespace, detectorOpts))
+		diffs = append(diffs, getUnusedReplicaSets(clientset, dynamicClient, namespace, detectorOpts))
+
+		unused := make(map[string]bool)
+		for _, diff := range diffs {
+			kind := diff.resourceType
+			if k, ok := resourceTypeKinds[kind]; ok {
+				kind = k
+			}
+			for _, resource := range diff.diff {
+				unused[namespaceObjectKey(kind, resource.Name)] = true
+			}
+		}
+		unusedContent[namespace] = unused
+	}
+
+	return unusedContent
+}
+
+// Find the namespace objects that are neither created by default nor considered unused by kor
+func retrieveRemainingNamespaceObjects(objects []unstructured.Unstructured, unused map[string]bool) []unstructured.Unstructured {
+	ignored := make(map[string]bool, len(unused))
+	for key := range unused {
+		ignored[key] = true
+	}
+	var candidates []unstructured.Unstructured
+	for _, object := range objects {
+		if isDefaultNamespaceObject(object) {
+			ignored[namespaceObjectKey(object.GetKind(), object.GetName())] = true
+			continue
+		}
+		candidates = append(candidates, object)
+	}
+
+	var remaining []unstructured.Unstructured
+	for _, object := range candidates {
+		if ignored[namespaceObjectKey(object.GetKind(), object.GetName())] {
+			continue
+		}
+
+		// Endpoints and EndpointSlices follow their Service
+		if object.GetKind() == "Endpoints" && ignored[namespaceObjectKey("Service", object.GetName())] {
+			continue
+		}
+
+		ownedByIgnored := false
+		for _, owner := range object.GetOwnerReferences() {
+			if ignored[namespaceObjectKey(owner.Kind, owner.Name)] {
+				ownedByIgnored = true
+				break
+			}
+		}
+		if ownedByIgnored {
+			continue
+		}
+
+		remaining = append(remaining, object)
+	}
+
+	return remaining
+}
+
+func terminatingNamespaceReason(namespace corev1.Namespace, objects []unstructured.Unstructured) string {
+	reason := "Namespace is stuck terminating"
+	if namespace.DeletionTimestamp != nil {
+		reason = fmt.Sprintf("%s since %s", reason, namespace.DeletionTimestamp.UTC().Format(time.RFC3339))
+	}
+
+	if len(namespace.Spec.Finalizers) > 0 {
+		finalizers := make([]string, 0, len(namespace.Spec.Finalizers))
+		for _, finalizer := range namespace.Spec.Finalizers {
+			finalizers = append(finalizers, string(finalizer))
+		}
+		reason = fmt.Sprintf("%s, finalizers: %s", reason, strings.Join(finalizers, ", "))
+	}
+
+	var remaining []string
+	for _, object := range objects {
+		if object.GetKind() == "Event" {
+			continue
+		}
+		remaining = append(remaining, namespaceObjectKey(object.GetKind(), object.GetName()))
+	}
+	if len(remaining) > 0 {
+		sort.Strings(remaining)
+		listed := remaining
+		if len(listed) > maxRemainingObjects {
+			listed = listed[:maxRemainingObjects]
+		}
+		reason = fmt.Sprintf("%s, remaining objects: %s", reason, strings.Join(listed, ", "))
+		if len(remaining) > maxRemainingObjects {
+			reason = fmt.Sprintf("%s and %d more", reason, len(remaining)-maxRemainingObjects)
+		}
+	}
+
+	for _, condition := range namespace.Status.Conditions {
+		if condition.Status == corev1.ConditionTrue && condition.Message != "" {
+			reason = fmt.Sprintf("%s, %s: %s", reason, condition.Type, condition.Message)
+		}
+	}
+
+	return reason
+}
+
+func processNamespaces(clientset kubernetes.Interface, dynamicClient dynamic.Interface, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	var unlisted []string
+	resourceTypes, err := clientset.Discovery().ServerPreferredNamespacedResources()
+	if err != nil {
+		if len(resourceTypes) == 0 {
+			return nil, err
+		}
+		fmt.Fprintf(os.Stderr, "Failed to discover some server resources: %v\n", err)
+		unlisted = append(unlisted, discoveryFailedGroups(err)...)
+	}
+
+	content, failedKinds, err := retrieveNamespacesContent(resourceTypes, dynamicClient)
+	if err != nil {
+		return nil, err
+	}
+	for _, kind := range failedKinds {
+		unlisted = append(unlisted, kind.String())
+	}
+
+	return processNamespacesContent(clientset, dynamicClient, content, unlisted, filterOpts)
+}
+
+// processNamespacesContent classifies the namespaces by their content, unlisted names the kinds or API groups whose objects are missing from it
+func processNamespacesContent(clientset kubernetes.Interface, dynamicClient dynamic.Interface, content map[string][]unstructured.Unstructured, unlisted []string, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	namespaces, err := clientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := unmarshalConfig(namespacesConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// Content that could not be listed may hold data, so such namespaces are reported without being classified as deletable
+	partialContentReason := ""
+	if len(unlisted) > 0 {
+		partialContentReason = fmt.Sprintf("Namespace contains only default objects and resources kor considers unused among the listed kinds, could not list %s", strings.Join(unlisted, ", "))
+	}
+
+	selectedNamespaces := filterOpts.Namespaces(clientset)
+	reasons := make(map[string]string)
+	var pending []string
+
+	for _, namespace := range namespaces.Items {
+		if !slices.Contains(selectedNamespaces, namespace.Name) {
+			continue
+		}
+
+		if pass, _ := filter.SetObject(&namespace).Run(filterOpts); pass {
+			continue
+		}
+
+		exceptionFound, err := isResourceException(namespace.Name, "", config.ExceptionNamespaces)
+		if err != nil {
+			return nil, err
+		}
+
+		if exceptionFound {
+			continue
+		}
+
+		if namespace.Labels["kor/used"] == "false" {
+			reasons[namespace.Name] = markedNamespaceReason
+			continue
+		}
+
+		objects := content[namespace.Name]
+
+		if namespace.Status.Phase == corev1.NamespaceTerminating {
+			reasons[namespace.Name] = terminatingNamespaceReason(namespace, objects)
+			continue
+		}
+
+		onlyDefaults := true
+		for _, object := range objects {
+			if !isDefaultNamespaceObject(object) {
+				onlyDefaults = false
+				break
+			}
+		}
+		if onlyDefaults {
+			if partialContentReason != "" {
+				reasons[namespace.Name] = partialContentReason
+			} else {
+				reasons[namespace.Name] = onlyDefaultsNamespaceReason
+			}
+			continue
+		}
+
+		pending = append(pending, namespace.Name)
+	}
+
+	unusedContent := retrieveUnusedNamespaceContent(clientset, dynamicClient, pending)
+	for _, namespace := range pending {
+		remaining := retrieveRemainingNamespaceObjects(content[namespace], unusedContent[namespace])
+		if len(remaining) == 0 {
+			if partialContentReason != "" {
+				reasons[namespace] = partialContentReason
+			} else {
+				reasons[namespace] = "Namespace contains only default objects and resources kor considers unused"
+			}
+			continue
+		}
+
+		hasWorkloads := false
+		for _, object := range remaining {
+			if slices.Contains(workloadKinds, object.GetKind()) {
+				hasWorkloads = true
+				break
+			}
+		}
+		if !hasWorkloads {
+			reasons[namespace] = "Namespace has no workloads or pods"
+		}
+	}
+
+	var unusedNamespaces []ResourceInfo
+	for _, namespace := range namespaces.Items {
+		if reason, ok := reasons[namespace.Name]; ok {
+			unusedNamespaces = append(unusedNamespaces, ResourceInfo{Name: namespace.Name, Reason: reason})
+		}
+	}
+
+	return unusedNamespaces, nil
+}
+
+// Delete the namespaces that hold only default objects or are marked unused, and keep reporting the others
+func deleteNamespaces(diff []ResourceInfo, clientset kubernetes.Interface, noInteractive bool) ([]ResourceInfo, error) {
+	var deletable, kept []ResourceInfo
+	for _, namespace := range diff {
+		if namespace.Reason == markedNamespaceReason || namespace.Reason == onlyDefaultsNamespaceReason {
+			deletable = append(deletable, namespace)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "Skipping deletion of Namespace %s, it holds more than default objects\n", namespace.Name)
+		kept = append(kept, namespace)
+	}
+
+	deleted, err := DeleteResource(deletable, clientset, "", "Namespace", noInteractive)
+	return append(deleted, kept...), err
+}
+
+func GetUnusedNamespaces(filterOpts *filters.Options, clientset kubernetes.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts) (string, error) {
+	resources := make(map[string]map[string][]ResourceInfo)
+	diff, err := processNamespaces(clientset, dynamicClient, filterOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to process namespaces: %v\n", err)
+	}
+	if opts.DeleteFlag {
+		if diff, err = deleteNamespaces(diff, clientset, opts.NoInteractive); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to delete Namespace %s: %v\n", diff, err)
+		}
+	}
+	switch opts.GroupBy {
+	case "namespace":
+		resources[""] = make(map[string][]ResourceInfo)
+		resources[""]["Namespace"] = diff
+	case "resource":
+		appendResources(resources, "Namespace", "", diff)
+	}
+
+	var outputBuffer bytes.Buffer
+	var jsonResponse []byte
+	switch outputFormat {
+	case "table":
+		outputBuffer = FormatOutput(resources, opts)
+	case "json", "yaml":
+		var err error
+		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
+			return "", err
+		}
+	}
+
+	unusedNamespaces, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
+	if err != nil {
+		fmt.Printf("err: %v\n", err)
+	}
+
+	return unusedNamespaces, nil
+}
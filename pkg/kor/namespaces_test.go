@@ -0,0 +1,218 @@
+package kor
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func createTestNamespaces(t *testing.T) (*fake.Clientset, map[string][]unstructured.Unstructured) {
+	clientset := fake.NewSimpleClientset()
+	content := make(map[string][]unstructured.Unstructured)
+
+	namespaces := []*corev1.Namespace{
+		{ObjectMeta: v1.ObjectMeta{Name: "ns-defaults"}},
+		{ObjectMeta: v1.ObjectMeta{Name: "ns-unused-content"}},
+		{ObjectMeta: v1.ObjectMeta{Name: "ns-no-workloads"}},
+		{ObjectMeta: v1.ObjectMeta{Name: "ns-active"}},
+		{ObjectMeta: v1.ObjectMeta{Name: "ns-used", Labels: UsedLabels}},
+		{ObjectMeta: v1.ObjectMeta{Name: "ns-unused", Labels: UnusedLabels}},
+		{ObjectMeta: v1.ObjectMeta{Name: "kube-system"}},
+		{
+			ObjectMeta: v1.ObjectMeta{Name: "ns-terminating"},
+			Spec:       corev1.NamespaceSpec{Finalizers: []corev1.FinalizerName{corev1.FinalizerKubernetes}},
+			Status: corev1.NamespaceStatus{
+				Phase: corev1.NamespaceTerminating,
+				Conditions: []corev1.NamespaceCondition{
+					{
+						Type:    corev1.NamespaceFinalizersRemaining,
+						Status:  corev1.ConditionTrue,
+						Message: "Some content in the namespace has finalizers remaining",
+					},
+				},
+			},
+		},
+	}
+
+	for _, namespace := range namespaces {
+		_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), namespace, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating namespace %s: %v", namespace.Name, err)
+		}
+
+		content[namespace.Name] = []unstructured.Unstructured{
+			*CreateTestUnstructered("ConfigMap", "v1", namespace.Name, "kube-root-ca.crt"),
+			*CreateTestUnstructered("ServiceAccount", "v1", namespace.Name, "default"),
+			*CreateTestUnstructered("Event", "v1", namespace.Name, "event"),
+		}
+	}
+
+	configmap := CreateTestConfigmap("ns-unused-content", "orphan-configmap", AppLabels)
+	_, err := clientset.CoreV1().ConfigMaps("ns-unused-content").Create(context.TODO(), configmap, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake configmap: %v", err)
+	}
+	content["ns-unused-content"] = append(content["ns-unused-content"],
+		*CreateTestUnstructered("ConfigMap", "v1", "ns-unused-content", "orphan-configmap"))
+
+	content["ns-no-workloads"] = append(content["ns-no-workloads"],
+		*CreateTestUnstructered("TestResource", "testgroup/v1", "ns-no-workloads", "test-resource"))
+
+	pod := CreateTestPod("ns-active", "web", "", nil, AppLabels)
+	_, err = clientset.CoreV1().Pods("ns-active").Create(context.TODO(), pod, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake pod: %v", err)
+	}
+	content["ns-active"] = append(content["ns-active"],
+		*CreateTestUnstructered("Pod", "v1", "ns-active", "web"))
+
+	content["ns-terminating"] = append(content["ns-terminating"],
+		*CreateTestUnstructered("TestResource", "testgroup/v1", "ns-terminating", "stuck-resource"))
+
+	return clientset, content
+}
+
+func TestProcessNamespacesContent(t *testing.T) {
+	clientset, content := createTestNamespaces(t)
+
+	unusedNamespaces, err := processNamespacesContent(clientset, fakedynamic.NewSimpleDynamicClient(runtime.NewScheme()), content, nil, filters.NewFilterOptions())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expectedReasons := map[string]string{
+		"ns-defaults":       "Namespace contains only default objects",
+		"ns-unused-content": "Namespace contains only default objects and resources kor considers unused",
+		"ns-no-workloads":   "Namespace has no workloads or pods",
+		"ns-unused":         "Marked with unused label",
+		"ns-terminating":    "Namespace is stuck terminating, finalizers: kubernetes, remaining objects: ConfigMap/kube-root-ca.crt, ServiceAccount/default, TestResource/stuck-resource, NamespaceFinalizersRemaining: Some content in the namespace has finalizers remaining",
+	}
+
+	if len(unusedNamespaces) != len(expectedReasons) {
+		t.Errorf("Expected %d unused namespaces, got %d: %v", len(expectedReasons), len(unusedNamespaces), unusedNamespaces)
+	}
+
+	for _, namespace := range unusedNamespaces {
+		expected, ok := expectedReasons[namespace.Name]
+		if !ok {
+			t.Errorf("Unexpected unused namespace %s", namespace.Name)
+			continue
+		}
+		if namespace.Reason != expected {
+			t.Errorf("Expected reason %q for namespace %s, got %q", expected, namespace.Name, namespace.Reason)
+		}
+	}
+}
+
+func TestDeleteNamespaces(t *testing.T) {
+	clientset, content := createTestNamespaces(t)
+
+	unusedNamespaces, err := processNamespacesContent(clientset, fakedynamic.NewSimpleDynamicClient(runtime.NewScheme()), content, nil, filters.NewFilterOptions())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err := deleteNamespaces(unusedNamespaces, clientset, true); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Only namespaces holding default objects or marked unused are deleted
+	for _, name := range []string{"ns-defaults", "ns-unused"} {
+		if _, err := clientset.CoreV1().Namespaces().Get(context.TODO(), name, v1.GetOptions{}); err == nil {
+			t.Errorf("Expected namespace %s to be deleted", name)
+		}
+	}
+	for _, name := range []string{"ns-unused-content", "ns-no-workloads", "ns-terminating"} {
+		if _, err := clientset.CoreV1().Namespaces().Get(context.TODO(), name, v1.GetOptions{}); err != nil {
+			t.Errorf("Expected namespace %s to be kept, got %v", name, err)
+		}
+	}
+}
+
+func TestProcessNamespacesPartialContent(t *testing.T) {
+	clientset, content := createTestNamespaces(t)
+
+	unusedNamespaces, err := processNamespacesContent(clientset, fakedynamic.NewSimpleDynamicClient(runtime.NewScheme()), content, []string{"testgroup/TestSecret"}, filters.NewFilterOptions())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	partialReason := "Namespace contains only default objects and resources kor considers unused among the listed kinds, could not list testgroup/TestSecret"
+	expectedReasons := map[string]string{
+		"ns-defaults":       partialReason,
+		"ns-unused-content": partialReason,
+		"ns-no-workloads":   "Namespace has no workloads or pods",
+		"ns-unused":         "Marked with unused label",
+	}
+	for _, namespace := range unusedNamespaces {
+		if expected, ok := expectedReasons[namespace.Name]; ok && namespace.Reason != expected {
+			t.Errorf("Expected reason %q for namespace %s, got %q", expected, namespace.Name, namespace.Reason)
+		}
+	}
+
+	if _, err := deleteNamespaces(unusedNamespaces, clientset, true); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Namespaces whose content could not be fully listed are never deleted
+	if _, err := clientset.CoreV1().Namespaces().Get(context.TODO(), "ns-defaults", v1.GetOptions{}); err != nil {
+		t.Errorf("Expected namespace %s to be kept, got %v", "ns-defaults", err)
+	}
+}
+
+func TestRetrieveNamespacesContent(t *testing.T) {
+	scheme := runtime.NewScheme()
+
+	dynamicClient := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(scheme,
+		map[schema.GroupVersionResource]string{
+			{Group: "testgroup", Version: "v1", Resource: "testresources"}: "TestResourceList",
+			{Group: "testgroup", Version: "v1", Resource: "testsecrets"}:   "TestSecretList",
+		},
+		CreateTestUnstructered("TestResource", "testgroup/v1", "ns-one", "resource-one"),
+		CreateTestUnstructered("TestResource", "testgroup/v1", "ns-two", "resource-two"),
+	)
+	dynamicClient.PrependReactor("list", "testsecrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.NewForbidden(schema.GroupResource{Group: "testgroup", Resource: "testsecrets"}, "", nil)
+	})
+
+	apiResourceLists := []*v1.APIResourceList{
+		{
+			GroupVersion: "testgroup/v1",
+			APIResources: []v1.APIResource{
+				{Name: "testresources", Kind: "TestResource", Verbs: []string{"list"}, Namespaced: true},
+				{Name: "testresources/status", Kind: "TestResource", Verbs: []string{"list"}, Namespaced: true},
+				{Name: "testreviews", Kind: "TestReview", Verbs: []string{"create"}, Namespaced: true},
+				{Name: "testsecrets", Kind: "TestSecret", Verbs: []string{"list"}, Namespaced: true},
+			},
+		},
+	}
+
+	content, failedKinds, err := retrieveNamespacesContent(apiResourceLists, dynamicClient)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expectedFailedKinds := []schema.GroupKind{{Group: "testgroup", Kind: "TestSecret"}}
+	if !reflect.DeepEqual(failedKinds, expectedFailedKinds) {
+		t.Errorf("Expected failed kinds %v, got %v", expectedFailedKinds, failedKinds)
+	}
+
+	for _, namespace := range []string{"ns-one", "ns-two"} {
+		if len(content[namespace]) != 1 || !strings.HasPrefix(content[namespace][0].GetName(), "resource-") {
+			t.Errorf("Expected one resource in namespace %s, got %v", namespace, content[namespace])
+		}
+	}
+}
@@ -6,11 +6,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"reflect"
 	"slices"
 
 	v1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/yonahd/kor/pkg/common"
@@ -21,99 +24,284 @@ const (
 	unusedLabelReason         = "Marked with unused label"
 	noPodAppliedReason        = "NetworkPolicy applies to 0 pods"
 	noPodAppliedByRulesReason = "NetworkPolicy Ingress and Egress rules apply to 0 pods"
+	noNamedPortReason         = "NetworkPolicy rules only allow named ports that none of the matched pods expose"
 )
 
-func retrievePodsForSelector(clientset kubernetes.Interface, namespace string, selector *metav1.LabelSelector) ([]v1.Pod, error) {
-	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+// Pods and namespaces listed at most once while the NetworkPolicies of a namespace are evaluated
+type networkPolicyPeerCache struct {
+	clientset  kubernetes.Interface
+	pods       map[string][]v1.Pod
+	namespaces []v1.Namespace
+}
+
+func newNetworkPolicyPeerCache(clientset kubernetes.Interface) *networkPolicyPeerCache {
+	return &networkPolicyPeerCache{
+		clientset: clientset,
+		pods:      make(map[string][]v1.Pod),
+	}
+}
+
+func (c *networkPolicyPeerCache) namespacePods(namespace string) ([]v1.Pod, error) {
+	if pods, ok := c.pods[namespace]; ok {
+		return pods, nil
+	}
+	podList, err := c.clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
 	if err != nil {
 		return nil, err
 	}
-	podList, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{
-		LabelSelector: labelSelector.String(),
-	})
+	c.pods[namespace] = podList.Items
+	return podList.Items, nil
+}
+
+// Pods of a namespace matched by a selector, a nil selector matches every pod
+func (c *networkPolicyPeerCache) selectPods(namespace string, selector *metav1.LabelSelector) ([]v1.Pod, error) {
+	labelSelector := labels.Everything()
+	if selector != nil {
+		var err error
+		if labelSelector, err = metav1.LabelSelectorAsSelector(selector); err != nil {
+			return nil, err
+		}
+	}
+
+	pods, err := c.namespacePods(namespace)
 	if err != nil {
 		return nil, err
 	}
 
-	return podList.Items, nil
+	var selected []v1.Pod
+	for _, pod := range pods {
+		if labelSelector.Matches(labels.Set(pod.Labels)) {
+			selected = append(selected, pod)
+		}
+	}
+	return selected, nil
 }
 
-func isAnyPodMatchedInSources(clientset kubernetes.Interface, sources []networkingv1.NetworkPolicyPeer) (bool, error) {
-	// If this field is empty or missing, this rule matches all pods
-	if len(sources) == 0 {
-		return true, nil
+func (c *networkPolicyPeerCache) selectNamespaces(selector *metav1.LabelSelector) ([]string, error) {
+	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.namespaces == nil {
+		nsList, err := c.clientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		c.namespaces = nsList.Items
 	}
 
-	for _, netpolPeer := range sources {
-		// If ipBlock is specified, assume the source is in use
-		if netpolPeer.IPBlock != nil {
-			return true, nil
+	var selected []string
+	for _, ns := range c.namespaces {
+		if labelSelector.Matches(labels.Set(ns.Labels)) {
+			selected = append(selected, ns.Name)
 		}
+	}
+	return selected, nil
+}
 
-		labelSelector, err := metav1.LabelSelectorAsSelector(netpolPeer.NamespaceSelector)
-		if err != nil {
-			return false, err
+// Find the pods matched by the peers of a rule, all is true when the rule is not restricted to pods
+func (c *networkPolicyPeerCache) peerPods(namespace string, peers []networkingv1.NetworkPolicyPeer) ([]v1.Pod, bool, error) {
+	// If this field is empty or missing, this rule matches all pods
+	if len(peers) == 0 {
+		return nil, true, nil
+	}
+
+	var matched []v1.Pod
+	for _, peer := range peers {
+		// If ipBlock is specified, assume the peer is in use
+		if peer.IPBlock != nil {
+			return nil, true, nil
 		}
 
-		nsList, err := clientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{
-			LabelSelector: labelSelector.String(),
-		})
-		if err != nil {
-			return false, err
+		// Without a namespaceSelector the podSelector applies to the namespace of the policy
+		namespaces := []string{namespace}
+		if peer.NamespaceSelector != nil {
+			var err error
+			if namespaces, err = c.selectNamespaces(peer.NamespaceSelector); err != nil {
+				return nil, false, err
+			}
 		}
 
-		for _, ns := range nsList.Items {
-			podList, err := retrievePodsForSelector(clientset, ns.Name, netpolPeer.PodSelector)
+		for _, ns := range namespaces {
+			pods, err := c.selectPods(ns, peer.PodSelector)
 			if err != nil {
-				return false, err
+				return nil, false, err
 			}
+			matched = append(matched, pods...)
+		}
+	}
 
-			if len(podList) > 0 {
-				return true, nil
+	return matched, false, nil
+}
+
+func isAnyPodMatchedInSources(cache *networkPolicyPeerCache, namespace string, sources []networkingv1.NetworkPolicyPeer) (bool, error) {
+	pods, all, err := cache.peerPods(namespace, sources)
+	if err != nil {
+		return false, err
+	}
+	return all || len(pods) > 0, nil
+}
+
+// Whether any of the pods exposes one of the ports, numeric ports cannot be checked and are assumed open
+func isAnyPortExposed(pods []v1.Pod, ports []networkingv1.NetworkPolicyPort) bool {
+	if len(ports) == 0 {
+		return true
+	}
+
+	for _, port := range ports {
+		if port.Port == nil || port.Port.Type == intstr.Int {
+			return true
+		}
+		protocol := v1.ProtocolTCP
+		if port.Protocol != nil {
+			protocol = *port.Protocol
+		}
+		for _, pod := range pods {
+			for _, container := range pod.Spec.Containers {
+				for _, containerPort := range container.Ports {
+					containerProtocol := containerPort.Protocol
+					if containerProtocol == "" {
+						containerProtocol = v1.ProtocolTCP
+					}
+					if containerPort.Name == port.Port.StrVal && containerProtocol == protocol {
+						return true
+					}
+				}
 			}
 		}
 	}
 
-	return false, nil
+	return false
 }
 
-func isAnyIngressRuleUsed(clientset kubernetes.Interface, netpol networkingv1.NetworkPolicy) (bool, error) {
+// Ingress ports are exposed by the pods the policy selects, the second result reports rules whose peers only miss a named port
+func isAnyIngressRuleUsed(cache *networkPolicyPeerCache, netpol networkingv1.NetworkPolicy, selectedPods []v1.Pod) (bool, bool, error) {
 	// Deny all ingress traffic
 	if len(netpol.Spec.Ingress) == 0 && slices.Contains(netpol.Spec.PolicyTypes, networkingv1.PolicyTypeIngress) {
-		return true, nil
+		return true, false, nil
 	}
+
+	portsUnmatched := false
 	for _, ingressRule := range netpol.Spec.Ingress {
-		podsMatched, err := isAnyPodMatchedInSources(clientset, ingressRule.From)
+		podsMatched, err := isAnyPodMatchedInSources(cache, netpol.Namespace, ingressRule.From)
 		if err != nil {
-			return false, err
+			return false, false, err
 		}
-
-		if podsMatched {
-			return true, nil
+		if !podsMatched {
+			continue
+		}
+		if !isAnyPortExposed(selectedPods, ingressRule.Ports) {
+			portsUnmatched = true
+			continue
 		}
+		return true, false, nil
 	}
 
-	return false, nil
+	return false, portsUnmatched, nil
 }
 
-func isAnyEgressRuleUsed(clientset kubernetes.Interface, netpol networkingv1.NetworkPolicy) (bool, error) {
+// Egress ports are exposed by the destination pods, the second result reports rules whose peers only miss a named port
+func isAnyEgressRuleUsed(cache *networkPolicyPeerCache, netpol networkingv1.NetworkPolicy) (bool, bool, error) {
 	// Deny all egress traffic
 	if len(netpol.Spec.Egress) == 0 && slices.Contains(netpol.Spec.PolicyTypes, networkingv1.PolicyTypeEgress) {
-		return true, nil
+		return true, false, nil
 	}
 
+	portsUnmatched := false
 	for _, egressRule := range netpol.Spec.Egress {
-		podsMatched, err := isAnyPodMatchedInSources(clientset, egressRule.To)
+		pods, all, err := cache.peerPods(netpol.Namespace, egressRule.To)
 		if err != nil {
-			return false, err
+			return false, false, err
+		}
+		if all {
+			return true, false, nil
+		}
+		if len(pods) == 0 {
+			continue
+		}
+		if !isAnyPortExposed(pods, egressRule.Ports) {
+			portsUnmatched = true
+			continue
+		}
+		return true, false, nil
+	}
+
+	return false, portsUnmatched, nil
+}
+
+func effectivePolicyTypes(netpol networkingv1.NetworkPolicy) []networkingv1.PolicyType {
+	if len(netpol.Spec.PolicyTypes) > 0 {
+		return netpol.Spec.PolicyTypes
+	}
+	policyTypes := []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}
+	if len(netpol.Spec.Egress) > 0 {
+		policyTypes = append(policyTypes, networkingv1.PolicyTypeEgress)
+	}
+	return policyTypes
+}
+
+func isSameLabelSelector(a, b metav1.LabelSelector) bool {
+	selectorA, err := metav1.LabelSelectorAsSelector(&a)
+	if err != nil {
+		return false
+	}
+	selectorB, err := metav1.LabelSelectorAsSelector(&b)
+	if err != nil {
+		return false
+	}
+	return selectorA.String() == selectorB.String()
+}
+
+// Whether policy isolates the same pods as other for at least the same directions and allows at least the same traffic
+func networkPolicyCovers(policy, other networkingv1.NetworkPolicy) bool {
+	if !isSameLabelSelector(policy.Spec.PodSelector, other.Spec.PodSelector) {
+		return false
+	}
+
+	policyTypes := effectivePolicyTypes(policy)
+	for _, policyType := range effectivePolicyTypes(other) {
+		if !slices.Contains(policyTypes, policyType) {
+			return false
 		}
+	}
 
-		if podsMatched {
-			return true, nil
+	for _, rule := range other.Spec.Ingress {
+		covered := slices.ContainsFunc(policy.Spec.Ingress, func(r networkingv1.NetworkPolicyIngressRule) bool {
+			return (len(r.From) == 0 && len(r.Ports) == 0) || reflect.DeepEqual(r, rule)
+		})
+		if !covered {
+			return false
+		}
+	}
+
+	for _, rule := range other.Spec.Egress {
+		covered := slices.ContainsFunc(policy.Spec.Egress, func(r networkingv1.NetworkPolicyEgressRule) bool {
+			return (len(r.To) == 0 && len(r.Ports) == 0) || reflect.DeepEqual(r, rule)
+		})
+		if !covered {
+			return false
 		}
 	}
 
-	return false, nil
+	return true
+}
+
+// Find a policy in effect that makes netpol redundant, identical policies only report the last one by name
+func shadowingNetworkPolicy(netpol networkingv1.NetworkPolicy, policies []networkingv1.NetworkPolicy, reported map[string]bool) string {
+	for _, other := range policies {
+		if other.Name == netpol.Name || reported[other.Name] {
+			continue
+		}
+		if !networkPolicyCovers(other, netpol) {
+			continue
+		}
+		if networkPolicyCovers(netpol, other) && netpol.Name < other.Name {
+			continue
+		}
+		return other.Name
+	}
+	return ""
 }
 
 func processNamespaceNetworkPolicies(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
@@ -123,6 +311,10 @@ func processNamespaceNetworkPolicies(clientset kubernetes.Interface, namespace s
 	}
 
 	var unusedNetpols []ResourceInfo
+	cache := newNetworkPolicyPeerCache(clientset)
+	reported := make(map[string]bool)
+	// Policies in use that may still be shadowed by another one
+	var inUse []networkingv1.NetworkPolicy
 
 	for _, netpol := range netpolList.Items {
 		if pass, _ := filter.SetObject(&netpol).Run(filterOpts); pass {
@@ -131,32 +323,49 @@ func processNamespaceNetworkPolicies(clientset kubernetes.Interface, namespace s
 
 		if netpol.Labels["kor/used"] == "false" {
 			unusedNetpols = append(unusedNetpols, ResourceInfo{Name: netpol.Name, Reason: unusedLabelReason})
+			reported[netpol.Name] = true
 			continue
 		}
 
-		pods, err := retrievePodsForSelector(clientset, namespace, &netpol.Spec.PodSelector)
+		pods, err := cache.selectPods(namespace, &netpol.Spec.PodSelector)
 		if err != nil {
 			return nil, err
 		}
 
 		if len(pods) == 0 {
 			unusedNetpols = append(unusedNetpols, ResourceInfo{Name: netpol.Name, Reason: noPodAppliedReason})
+			reported[netpol.Name] = true
 			continue
 		}
 
-		if used, err := isAnyIngressRuleUsed(clientset, netpol); err != nil {
+		ingressUsed, ingressPortsUnmatched, err := isAnyIngressRuleUsed(cache, netpol, pods)
+		if err != nil {
 			return nil, err
-		} else if used {
-			continue
 		}
 
-		if used, err := isAnyEgressRuleUsed(clientset, netpol); err != nil {
+		egressUsed, egressPortsUnmatched, err := isAnyEgressRuleUsed(cache, netpol)
+		if err != nil {
 			return nil, err
-		} else if used {
+		}
+
+		if ingressUsed || egressUsed {
+			inUse = append(inUse, netpol)
 			continue
 		}
 
-		unusedNetpols = append(unusedNetpols, ResourceInfo{Name: netpol.Name, Reason: noPodAppliedByRulesReason})
+		reason := noPodAppliedByRulesReason
+		if ingressPortsUnmatched || egressPortsUnmatched {
+			reason = noNamedPortReason
+		}
+		unusedNetpols = append(unusedNetpols, ResourceInfo{Name: netpol.Name, Reason: reason})
+		reported[netpol.Name] = true
+	}
+
+	for _, netpol := range inUse {
+		if shadow := shadowingNetworkPolicy(netpol, netpolList.Items, reported); shadow != "" {
+			reason := fmt.Sprintf("NetworkPolicy is shadowed by %s, which selects the same pods with a superset of its rules", shadow)
+			unusedNetpols = append(unusedNetpols, ResourceInfo{Name: netpol.Name, Reason: reason})
+		}
 	}
 
 	return unusedNetpols, nil
@@ -10,6 +10,7 @@ import (
 	networkingv1 "k8s.io/api/networking/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/kubernetes/scheme"
 
@@ -112,6 +113,34 @@ func createTestNetworkPolicies(t *testing.T) *fake.Clientset {
 	netpol9.Spec.PolicyTypes = []networkingv1.PolicyType{networkingv1.PolicyTypeEgress}
 	netpols = append(netpols, netpol9)
 
+	// egress to pods of another namespace, without a namespaceSelector it would only match the own namespace
+	crossNamespaceEgress := []networkingv1.NetworkPolicyEgressRule{{
+		To: []networkingv1.NetworkPolicyPeer{
+			{
+				NamespaceSelector: &v1.LabelSelector{},
+				PodSelector:       v1.SetAsLabelSelector(podLabels2),
+			},
+		},
+	}}
+	netpolA := CreateTestNetworkPolicy("netpol-a", testNamespace, AppLabels, *v1.SetAsLabelSelector(podLabels1), nil, crossNamespaceEgress)
+	netpolA.Spec.PolicyTypes = []networkingv1.PolicyType{networkingv1.PolicyTypeEgress}
+	// identical to netpol-a
+	netpolC := CreateTestNetworkPolicy("netpol-c", testNamespace, AppLabels, *v1.SetAsLabelSelector(podLabels1), nil, crossNamespaceEgress)
+	netpolC.Spec.PolicyTypes = []networkingv1.PolicyType{networkingv1.PolicyTypeEgress}
+
+	// ingress on a named port the selected pods do not expose
+	metricsPort := intstr.FromString("metrics")
+	netpolB := CreateTestNetworkPolicy("netpol-b", testNamespace, AppLabels, *v1.SetAsLabelSelector(podLabels1), []networkingv1.NetworkPolicyIngressRule{{
+		From: []networkingv1.NetworkPolicyPeer{
+			{
+				PodSelector: v1.SetAsLabelSelector(AppLabels),
+			},
+		},
+		Ports: []networkingv1.NetworkPolicyPort{{Port: &metricsPort}},
+	}}, nil)
+
+	netpols = append(netpols, netpolA, netpolB, netpolC)
+
 	for _, netpol := range netpols {
 		_, err := clientset.NetworkingV1().NetworkPolicies(netpol.Namespace).Create(context.TODO(), netpol, v1.CreateOptions{})
 		if err != nil {
@@ -122,7 +151,7 @@ func createTestNetworkPolicies(t *testing.T) *fake.Clientset {
 	return clientset
 }
 
-func TestRetrievePodsForSelector(t *testing.T) {
+func TestNetworkPolicyPeerCacheSelectPods(t *testing.T) {
 	clientset := createTestNetworkPolicies(t)
 
 	selector := &v1.LabelSelector{
@@ -130,7 +159,7 @@ func TestRetrievePodsForSelector(t *testing.T) {
 			"app.kubernetes.io/version": "v1",
 		},
 	}
-	pods, err := retrievePodsForSelector(clientset, testNamespace, selector)
+	pods, err := newNetworkPolicyPeerCache(clientset).selectPods(testNamespace, selector)
 	if err != nil {
 		t.Errorf("Error retrieving pods for selector %v: %v", selector, err)
 	}
@@ -163,7 +192,9 @@ func TestIsAnyPodMatchedInSources(t *testing.T) {
 		},
 	}
 
-	matched, err := isAnyPodMatchedInSources(clientset, sources)
+	cache := newNetworkPolicyPeerCache(clientset)
+
+	matched, err := isAnyPodMatchedInSources(cache, testNamespace, sources)
 	if err != nil {
 		t.Errorf("Error checking if sources match any pods: %v", err)
 	}
@@ -171,6 +202,29 @@ func TestIsAnyPodMatchedInSources(t *testing.T) {
 	if !matched {
 		t.Error("Expected matching pods, got none")
 	}
+
+	// Without a namespaceSelector only the pods of the policy namespace are matched
+	sources[0].PodSelector = v1.SetAsLabelSelector(map[string]string{"app.kubernetes.io/version": "v2"})
+
+	matched, err = isAnyPodMatchedInSources(cache, testNamespace, sources)
+	if err != nil {
+		t.Errorf("Error checking if sources match any pods: %v", err)
+	}
+
+	if matched {
+		t.Error("Expected no matching pods in the policy namespace, got some")
+	}
+
+	sources[0].NamespaceSelector = &v1.LabelSelector{}
+
+	matched, err = isAnyPodMatchedInSources(cache, testNamespace, sources)
+	if err != nil {
+		t.Errorf("Error checking if sources match any pods: %v", err)
+	}
+
+	if !matched {
+		t.Error("Expected matching pods in other namespaces, got none")
+	}
 }
 
 func TestIsAnyIngressRuleUsed(t *testing.T) {
@@ -178,7 +232,7 @@ func TestIsAnyIngressRuleUsed(t *testing.T) {
 
 	netpol := CreateTestNetworkPolicy("netpol-0", testNamespace, AppLabels, v1.LabelSelector{}, nil, nil)
 
-	used, err := isAnyIngressRuleUsed(clientset, *netpol)
+	used, _, err := isAnyIngressRuleUsed(newNetworkPolicyPeerCache(clientset), *netpol, nil)
 	if err != nil {
 		t.Errorf("Error checking if any ingress rule is used: %v", err)
 	}
@@ -193,7 +247,7 @@ func TestIsAnyEgressRuleUsed(t *testing.T) {
 
 	netpol := CreateTestNetworkPolicy("netpol-0", testNamespace, AppLabels, v1.LabelSelector{}, nil, nil)
 
-	used, err := isAnyEgressRuleUsed(clientset, *netpol)
+	used, _, err := isAnyEgressRuleUsed(newNetworkPolicyPeerCache(clientset), *netpol)
 	if err != nil {
 		t.Errorf("Error checking if any egress rule is used: %v", err)
 	}
@@ -211,21 +265,20 @@ func TestProcessNamespaceNetworkPolicies(t *testing.T) {
 		t.Errorf("Expected no error, got %v", err)
 	}
 
-	expectedUnusedNetpols := []string{
-		"netpol-2",
-		"netpol-3",
-		"netpol-7",
-		"netpol-9",
+	expectedUnusedNetpols := []ResourceInfo{
+		{Name: "netpol-2", Reason: unusedLabelReason},
+		{Name: "netpol-3", Reason: noPodAppliedReason},
+		{Name: "netpol-6", Reason: noPodAppliedByRulesReason},
+		{Name: "netpol-7", Reason: noPodAppliedByRulesReason},
+		{Name: "netpol-9", Reason: noPodAppliedByRulesReason},
+		{Name: "netpol-b", Reason: noNamedPortReason},
+		{Name: "netpol-4", Reason: "NetworkPolicy is shadowed by netpol-5, which selects the same pods with a superset of its rules"},
+		{Name: "netpol-8", Reason: "NetworkPolicy is shadowed by netpol-5, which selects the same pods with a superset of its rules"},
+		{Name: "netpol-c", Reason: "NetworkPolicy is shadowed by netpol-a, which selects the same pods with a superset of its rules"},
 	}
 
-	if len(unusedNetpols) != len(expectedUnusedNetpols) {
-		t.Errorf("Expected %d unused networkpolicies, got %d", len(expectedUnusedNetpols), len(unusedNetpols))
-	}
-
-	for i, netpol := range unusedNetpols {
-		if netpol.Name != expectedUnusedNetpols[i] {
-			t.Errorf("Expected unused networkpolicy %s, got %s", expectedUnusedNetpols[i], netpol)
-		}
+	if !reflect.DeepEqual(unusedNetpols, expectedUnusedNetpols) {
+		t.Errorf("Expected %v, got %v", expectedUnusedNetpols, unusedNetpols)
 	}
 }
 
@@ -251,8 +304,13 @@ func TestGetUnusedNetworkPolicies(t *testing.T) {
 			"NetworkPolicy": []string{
 				"netpol-2",
 				"netpol-3",
+				"netpol-6",
 				"netpol-7",
 				"netpol-9",
+				"netpol-b",
+				"netpol-4",
+				"netpol-8",
+				"netpol-c",
 			},
 		},
 	}
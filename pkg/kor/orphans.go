@@ -0,0 +1,191 @@
+package kor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/strings/slices"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+// ownerIdentity locates an object that may be referenced as an owner
+type ownerIdentity struct {
+	namespace string
+	kind      string
+	name      string
+	uid       types.UID
+}
+
+func ownerGroupName(owner metav1.OwnerReference) string {
+	return owner.Kind + "/" + owner.Name
+}
+
+// Explain why an ownerReference is dangling, or return an empty string when the owner is valid
+func danglingOwnerReason(dependent unstructured.Unstructured, owner metav1.OwnerReference, byUID map[types.UID]ownerIdentity, byName map[string]ownerIdentity) string {
+	if identity, exists := byUID[owner.UID]; exists {
+		if identity.namespace == "" || identity.namespace == dependent.GetNamespace() {
+			return ""
+		}
+		if dependent.GetNamespace() == "" {
+			return fmt.Sprintf("Cluster-scoped object references namespaced owner in namespace %s", identity.namespace)
+		}
+		return fmt.Sprintf("Owner exists in namespace %s, cross-namespace owner references are not allowed", identity.namespace)
+	}
+
+	for _, namespace := range []string{dependent.GetNamespace(), ""} {
+		if identity, exists := byName[namespace+"/"+owner.Kind+"/"+owner.Name]; exists {
+			return fmt.Sprintf("Owner was recreated with a different UID %s, expected %s", identity.uid, owner.UID)
+		}
+	}
+
+	return fmt.Sprintf("Owner with UID %s no longer exists", owner.UID)
+}
+
+// Owners of a kind that could not be listed, or of a group whose discovery failed, cannot be told apart from missing owners
+func isUnresolvableOwner(owner metav1.OwnerReference, failedKinds []schema.GroupKind, failedGroups []string) bool {
+	gv, err := schema.ParseGroupVersion(owner.APIVersion)
+	if err != nil {
+		return true
+	}
+	if slices.Contains(failedGroups, gv.Group) {
+		return true
+	}
+	for _, kind := range failedKinds {
+		if kind == gv.WithKind(owner.Kind).GroupKind() {
+			return true
+		}
+	}
+	return false
+}
+
+func retrieveOrphanedResources(resourceTypes []*metav1.APIResourceList, failedGroups []string, dynamicClient dynamic.Interface, namespaces []string, filterOpts *filters.Options) (map[string]map[string][]ResourceInfo, error) {
+	orphanedResources := make(map[string]map[string][]ResourceInfo) //map[namespace]map[owner][]dependents
+
+	byUID := make(map[types.UID]ownerIdentity)
+	byName := make(map[string]ownerIdentity)
+	var dependents []unstructured.Unstructured
+
+	failedKinds, err := walkDiscoveredResources(resourceTypes, dynamicClient, metav1.ListOptions{}, func(_ schema.GroupVersionResource, _ metav1.APIResource, item unstructured.Unstructured) {
+		identity := ownerIdentity{namespace: item.GetNamespace(), kind: item.GetKind(), name: item.GetName(), uid: item.GetUID()}
+		byUID[identity.uid] = identity
+		byName[identity.namespace+"/"+identity.kind+"/"+identity.name] = identity
+
+		if len(item.GetOwnerReferences()) == 0 {
+			return
+		}
+		if item.GetNamespace() != "" && !slices.Contains(namespaces, item.GetNamespace()) {
+			return
+		}
+		if pass, _ := filter.SetObject(&item).Run(filterOpts); pass {
+			return
+		}
+		dependents = append(dependents, item)
+	})
+	if err != nil {
+		return orphanedResources, err
+	}
+
+	// All objects have to be known before deciding whether an owner is gone
+	for _, dependent := range dependents {
+		for _, owner := range dependent.GetOwnerReferences() {
+			if _, exists := byUID[owner.UID]; !exists && isUnresolvableOwner(owner, failedKinds, failedGroups) {
+				continue
+			}
+			reason := danglingOwnerReason(dependent, owner, byUID, byName)
+			if reason == "" {
+				continue
+			}
+
+			namespace := dependent.GetNamespace()
+			if orphanedResources[namespace] == nil {
+				orphanedResources[namespace] = make(map[string][]ResourceInfo)
+			}
+			group := ownerGroupName(owner)
+			orphanedResources[namespace][group] = append(orphanedResources[namespace][group], ResourceInfo{
+				Name:   dependent.GetKind() + "/" + dependent.GetName(),
+				Reason: reason,
+			})
+		}
+	}
+
+	for _, groups := range orphanedResources {
+		for _, orphans := range groups {
+			sort.Slice(orphans, func(i, j int) bool {
+				return orphans[i].Name < orphans[j].Name
+			})
+		}
+	}
+
+	return orphanedResources, nil
+}
+
+func GetOrphanedResources(filterOpts *filters.Options, clientset kubernetes.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts) (string, error) {
+	if opts.DeleteFlag {
+		fmt.Fprintf(os.Stderr, "Deleting orphaned resources is not supported, ignoring --delete flag\n")
+	}
+
+	resourceTypes, err := clientset.Discovery().ServerPreferredResources()
+	var failedGroups []string
+	if err != nil {
+		if len(resourceTypes) == 0 {
+			return "", err
+		}
+		fmt.Fprintf(os.Stderr, "Failed to discover some server resources: %v\n", err)
+		if groupErr, ok := err.(*discovery.ErrGroupDiscoveryFailed); ok {
+			for gv := range groupErr.Groups {
+				failedGroups = append(failedGroups, gv.Group)
+			}
+		}
+	}
+
+	orphanedResources, err := retrieveOrphanedResources(resourceTypes, failedGroups, dynamicClient, filterOpts.Namespaces(clientset), filterOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to process orphaned resources: %v\n", err)
+	}
+
+	resources := make(map[string]map[string][]ResourceInfo)
+	for namespace, groups := range orphanedResources {
+		for owner, orphans := range groups {
+			switch opts.GroupBy {
+			case "namespace":
+				if resources[namespace] == nil {
+					resources[namespace] = make(map[string][]ResourceInfo)
+				}
+				resources[namespace][owner] = orphans
+			case "resource":
+				appendResources(resources, owner, namespace, orphans)
+			}
+		}
+	}
+
+	var outputBuffer bytes.Buffer
+	var jsonResponse []byte
+	switch outputFormat {
+	case "table":
+		outputBuffer = FormatOutput(resources, opts)
+	case "json", "yaml":
+		var err error
+		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
+			return "", err
+		}
+	}
+
+	unusedOrphans, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
+	if err != nil {
+		fmt.Printf("err: %v\n", err)
+	}
+
+	return unusedOrphans, nil
+}
@@ -0,0 +1,129 @@
+package kor
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func createTestOwnedUnstructured(kind, namespace, name string, uid types.UID, owners ...metav1.OwnerReference) *unstructured.Unstructured {
+	object := CreateTestUnstructered(kind, "testgroup/v1", namespace, name)
+	object.SetUID(uid)
+	object.SetOwnerReferences(owners)
+	return object
+}
+
+func TestRetrieveOrphanedResources(t *testing.T) {
+	scheme := runtime.NewScheme()
+
+	ownerRef := func(name string, uid types.UID) metav1.OwnerReference {
+		return metav1.OwnerReference{APIVersion: "testgroup/v1", Kind: "TestOwner", Name: name, UID: uid}
+	}
+
+	dynamicClient := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(scheme,
+		map[schema.GroupVersionResource]string{
+			{Group: "testgroup", Version: "v1", Resource: "testowners"}:     "TestOwnerList",
+			{Group: "testgroup", Version: "v1", Resource: "testdependents"}: "TestDependentList",
+		},
+		createTestOwnedUnstructured("TestOwner", testNamespace, "owner", "owner-uid"),
+		createTestOwnedUnstructured("TestOwner", "other-namespace", "foreign-owner", "foreign-uid"),
+		createTestOwnedUnstructured("TestOwner", testNamespace, "recreated-owner", "new-uid"),
+		createTestOwnedUnstructured("TestDependent", testNamespace, "owned", "owned-uid", ownerRef("owner", "owner-uid")),
+		createTestOwnedUnstructured("TestDependent", testNamespace, "orphan", "orphan-uid", ownerRef("deleted-owner", "deleted-uid")),
+		createTestOwnedUnstructured("TestDependent", testNamespace, "cross-namespace", "cross-uid", ownerRef("foreign-owner", "foreign-uid")),
+		createTestOwnedUnstructured("TestDependent", testNamespace, "stale", "stale-uid", ownerRef("recreated-owner", "old-uid")),
+		createTestOwnedUnstructured("TestDependent", "ignored-namespace", "ignored", "ignored-uid", ownerRef("deleted-owner", "deleted-uid")),
+	)
+
+	apiResourceLists := []*metav1.APIResourceList{
+		{
+			GroupVersion: "testgroup/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "testowners", Kind: "TestOwner", Verbs: []string{"list"}, Namespaced: true},
+				{Name: "testdependents", Kind: "TestDependent", Verbs: []string{"list"}, Namespaced: true},
+			},
+		},
+	}
+
+	result, err := retrieveOrphanedResources(apiResourceLists, nil, dynamicClient, []string{testNamespace, "other-namespace"}, &filters.Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := map[string]ResourceInfo{
+		"TestOwner/deleted-owner":   {Name: "TestDependent/orphan", Reason: "Owner with UID deleted-uid no longer exists"},
+		"TestOwner/foreign-owner":   {Name: "TestDependent/cross-namespace", Reason: "Owner exists in namespace other-namespace, cross-namespace owner references are not allowed"},
+		"TestOwner/recreated-owner": {Name: "TestDependent/stale", Reason: "Owner was recreated with a different UID new-uid, expected old-uid"},
+	}
+
+	if len(result) != 1 {
+		t.Fatalf("Expected orphans in a single namespace, got %v", result)
+	}
+
+	if len(result[testNamespace]) != len(expected) {
+		t.Errorf("Expected %d missing owners, got %d: %v", len(expected), len(result[testNamespace]), result[testNamespace])
+	}
+
+	for owner, orphan := range expected {
+		orphans := result[testNamespace][owner]
+		if len(orphans) != 1 || orphans[0] != orphan {
+			t.Errorf("Expected %v for owner %s, got %v", orphan, owner, orphans)
+		}
+	}
+}
+
+func TestRetrieveOrphanedResourcesSkipsUnlistedOwners(t *testing.T) {
+	scheme := runtime.NewScheme()
+
+	dynamicClient := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(scheme,
+		map[schema.GroupVersionResource]string{
+			{Group: "testgroup", Version: "v1", Resource: "testowners"}:     "TestOwnerList",
+			{Group: "testgroup", Version: "v1", Resource: "testdependents"}: "TestDependentList",
+		},
+		createTestOwnedUnstructured("TestOwner", testNamespace, "owner", "owner-uid"),
+		createTestOwnedUnstructured("TestDependent", testNamespace, "owned", "owned-uid",
+			metav1.OwnerReference{APIVersion: "testgroup/v1", Kind: "TestOwner", Name: "owner", UID: "owner-uid"}),
+		createTestOwnedUnstructured("TestDependent", testNamespace, "undiscovered", "undiscovered-uid",
+			metav1.OwnerReference{APIVersion: "unavailable.example.com/v1", Kind: "Widget", Name: "widget", UID: "widget-uid"}),
+		createTestOwnedUnstructured("TestDependent", testNamespace, "orphan", "orphan-uid",
+			metav1.OwnerReference{APIVersion: "testgroup/v1", Kind: "TestDependent", Name: "deleted", UID: "deleted-uid"}),
+	)
+	dynamicClient.PrependReactor("list", "testowners", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.NewForbidden(schema.GroupResource{Group: "testgroup", Resource: "testowners"}, "", nil)
+	})
+
+	apiResourceLists := []*metav1.APIResourceList{
+		{
+			GroupVersion: "testgroup/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "testowners", Kind: "TestOwner", Verbs: []string{"list"}, Namespaced: true},
+				{Name: "testdependents", Kind: "TestDependent", Verbs: []string{"list"}, Namespaced: true},
+			},
+		},
+	}
+
+	result, err := retrieveOrphanedResources(apiResourceLists, []string{"unavailable.example.com"}, dynamicClient, []string{testNamespace}, &filters.Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Owners of the unlisted TestOwner kind and of the undiscovered group are unknown, not missing
+	expected := map[string]map[string][]ResourceInfo{
+		testNamespace: {
+			"TestDependent/deleted": {{Name: "TestDependent/orphan", Reason: "Owner with UID deleted-uid no longer exists"}},
+		},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
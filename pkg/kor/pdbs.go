@@ -7,9 +7,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	labels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
 
@@ -20,6 +27,166 @@ import (
 //go:embed exceptions/pdbs/pdbs.json
 var pdbsConfig []byte
 
+// Workloads of a namespace that PDB selectors are evaluated against
+type pdbWorkloads struct {
+	deployments  []appsv1.Deployment
+	statefulSets []appsv1.StatefulSet
+	replicaSets  []appsv1.ReplicaSet
+	daemonSets   []appsv1.DaemonSet
+	jobs         []batchv1.Job
+	pods         []corev1.Pod
+}
+
+func retrievePdbWorkloads(clientset kubernetes.Interface, namespace string) (*pdbWorkloads, error) {
+	deployments, err := clientset.AppsV1().Deployments(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	statefulSets, err := clientset.AppsV1().StatefulSets(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	replicaSets, err := clientset.AppsV1().ReplicaSets(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	daemonSets, err := clientset.AppsV1().DaemonSets(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	jobs, err := clientset.BatchV1().Jobs(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pdbWorkloads{
+		deployments:  deployments.Items,
+		statefulSets: statefulSets.Items,
+		replicaSets:  replicaSets.Items,
+		daemonSets:   daemonSets.Items,
+		jobs:         jobs.Items,
+		pods:         pods.Items,
+	}, nil
+}
+
+// Replicas expected from the workloads whose pods a selector matches, keyed by Kind/Name
+func (w *pdbWorkloads) selectedBy(selector labels.Selector) map[string]int32 {
+	selected := make(map[string]int32)
+	// Workloads already counted through their own template
+	fromTemplates := make(map[string]bool)
+
+	for _, deployment := range w.deployments {
+		if selector.Matches(labels.Set(deployment.Spec.Template.Labels)) {
+			selected["Deployment/"+deployment.Name] = desiredReplicas(deployment.Spec.Replicas)
+			fromTemplates["Deployment/"+deployment.Name] = true
+		}
+	}
+
+	for _, statefulSet := range w.statefulSets {
+		if selector.Matches(labels.Set(statefulSet.Spec.Template.Labels)) {
+			selected["StatefulSet/"+statefulSet.Name] = desiredReplicas(statefulSet.Spec.Replicas)
+			fromTemplates["StatefulSet/"+statefulSet.Name] = true
+		}
+	}
+
+	for _, daemonSet := range w.daemonSets {
+		if selector.Matches(labels.Set(daemonSet.Spec.Template.Labels)) {
+			selected["DaemonSet/"+daemonSet.Name] = daemonSet.Status.DesiredNumberScheduled
+			fromTemplates["DaemonSet/"+daemonSet.Name] = true
+		}
+	}
+
+	for _, job := range w.jobs {
+		if selector.Matches(labels.Set(job.Spec.Template.Labels)) {
+			selected["Job/"+job.Name] = job.Status.Active
+			fromTemplates["Job/"+job.Name] = true
+		}
+	}
+
+	// Scale subresource workloads such as Argo Rollouts manage their pods through ReplicaSets
+	replicaSetOwners := make(map[string]string, len(w.replicaSets))
+	for i := range w.replicaSets {
+		replicaSet := &w.replicaSets[i]
+		workload := "ReplicaSet/" + replicaSet.Name
+		if owner := metav1.GetControllerOf(replicaSet); owner != nil {
+			workload = owner.Kind + "/" + owner.Name
+		}
+		replicaSetOwners[replicaSet.Name] = workload
+		if fromTemplates[workload] || !selector.Matches(labels.Set(replicaSet.Spec.Template.Labels)) {
+			continue
+		}
+		selected[workload] += desiredReplicas(replicaSet.Spec.Replicas)
+	}
+
+	// Pods of any other controller are counted through their ownerReferences
+	fromPods := make(map[string]bool)
+	for i := range w.pods {
+		pod := &w.pods[i]
+		if pod.DeletionTimestamp != nil || !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		workload := "Pod/" + pod.Name
+		if owner := metav1.GetControllerOf(pod); owner != nil {
+			workload = owner.Kind + "/" + owner.Name
+			if owner.Kind == "ReplicaSet" && replicaSetOwners[owner.Name] != "" {
+				workload = replicaSetOwners[owner.Name]
+			}
+		}
+		if _, counted := selected[workload]; counted && !fromPods[workload] {
+			continue
+		}
+		fromPods[workload] = true
+		selected[workload]++
+	}
+
+	return selected
+}
+
+// isEmptySelector reports a non-null selector without requirements, which selects every object
+func isEmptySelector(selector *metav1.LabelSelector) bool {
+	return selector != nil && len(selector.MatchLabels) == 0 && len(selector.MatchExpressions) == 0
+}
+
+// Explain why a PDB can never allow a voluntary eviction of the pods it selects, or return an empty string
+func blockingPdbReason(pdb policyv1.PodDisruptionBudget, workloads map[string]int32) string {
+	var expectedPods int32
+	names := make([]string, 0, len(workloads))
+	for name, replicas := range workloads {
+		expectedPods += replicas
+		names = append(names, name)
+	}
+	// Nothing to evict while the workloads are scaled to zero
+	if expectedPods == 0 {
+		return ""
+	}
+	sort.Strings(names)
+
+	if pdb.Spec.MaxUnavailable != nil {
+		maxUnavailable, err := intstr.GetScaledValueFromIntOrPercent(pdb.Spec.MaxUnavailable, int(expectedPods), true)
+		if err == nil && maxUnavailable <= 0 {
+			return fmt.Sprintf("Pdb maxUnavailable of %s never allows a voluntary eviction of the %d pods of %s", pdb.Spec.MaxUnavailable.String(), expectedPods, strings.Join(names, ", "))
+		}
+	}
+
+	if pdb.Spec.MinAvailable != nil {
+		minAvailable, err := intstr.GetScaledValueFromIntOrPercent(pdb.Spec.MinAvailable, int(expectedPods), true)
+		if err == nil && minAvailable >= int(expectedPods) {
+			return fmt.Sprintf("Pdb minAvailable of %s never allows a voluntary eviction of the %d pods of %s", pdb.Spec.MinAvailable.String(), expectedPods, strings.Join(names, ", "))
+		}
+	}
+
+	return ""
+}
+
 func processNamespacePdbs(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
 	var unusedPdbs []ResourceInfo
 	pdbs, err := clientset.PolicyV1().PodDisruptionBudgets(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
@@ -32,6 +199,8 @@ func processNamespacePdbs(clientset kubernetes.Interface, namespace string, filt
 		return nil, err
 	}
 
+	var workloads *pdbWorkloads
+
 	for _, pdb := range pdbs.Items {
 		if pass, _ := filter.SetObject(&pdb).Run(filterOpts); pass {
 			continue
@@ -53,10 +222,16 @@ func processNamespacePdbs(clientset kubernetes.Interface, namespace string, filt
 		}
 
 		selector := pdb.Spec.Selector
-		var hasMatchingTemplates, hasMatchingWorkloads bool
+
+		// In policy/v1 a null selector selects no pods
+		if selector == nil {
+			reason := "Pdb has a null selector and selects no pods"
+			unusedPdbs = append(unusedPdbs, ResourceInfo{Name: pdb.Name, Reason: reason})
+			continue
+		}
 
 		// Validate empty selector
-		if selector == nil || len(selector.MatchLabels) == 0 {
+		if isEmptySelector(selector) {
 			hasRunningPods, err := validateRunningPods(clientset, namespace)
 			if err != nil {
 				return nil, err
@@ -68,20 +243,21 @@ func processNamespacePdbs(clientset kubernetes.Interface, namespace string, filt
 			}
 
 			continue
-		} else {
-			hasMatchingTemplates, err = validateMatchingTemplates(clientset, namespace, selector)
-			if err != nil {
-				return nil, err
-			}
+		}
 
-			hasMatchingWorkloads, err = validateMatchingWorkloads(clientset, namespace, selector)
-			if err != nil {
+		labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+		if err != nil {
+			return nil, err
+		}
+
+		if workloads == nil {
+			if workloads, err = retrievePdbWorkloads(clientset, namespace); err != nil {
 				return nil, err
 			}
 		}
 
-		if !hasMatchingTemplates && !hasMatchingWorkloads {
-			reason := "Pdb is not referencing any deployments, statefulsets or pods"
+		if len(workloads.selectedBy(labelSelector)) == 0 {
+			reason := "Pdb is not referencing any workloads or pods"
 			unusedPdbs = append(unusedPdbs, ResourceInfo{Name: pdb.Name, Reason: reason})
 		}
 	}
@@ -89,67 +265,72 @@ func processNamespacePdbs(clientset kubernetes.Interface, namespace string, filt
 	return unusedPdbs, nil
 }
 
-func validateRunningPods(clientset kubernetes.Interface, namespace string) (bool, error) {
-	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{
-		FieldSelector: "status.phase=Running",
-	})
+// PDBs that block every voluntary eviction are in use, they are reported apart from unused ones and never deleted
+func processNamespaceBlockingPdbs(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	pdbs, err := clientset.PolicyV1().PodDisruptionBudgets(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	// Field status.phase=Running can still reference Terminating pods
-	// Return true if at least one pod is running
-	for _, pod := range pods.Items {
-		if pod.ObjectMeta.DeletionTimestamp == nil {
-			return true, nil
-		}
+	config, err := unmarshalConfig(pdbsConfig)
+	if err != nil {
+		return nil, err
 	}
 
-	return false, nil
-}
+	var workloads *pdbWorkloads
+	var blockingPdbs []ResourceInfo
 
-func validateMatchingTemplates(clientset kubernetes.Interface, namespace string, selector *metav1.LabelSelector) (bool, error) {
-	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
-	if err != nil {
-		return false, err
-	}
+	for _, pdb := range pdbs.Items {
+		if pass, _ := filter.SetObject(&pdb).Run(filterOpts); pass {
+			continue
+		}
 
-	deployments, err := clientset.AppsV1().Deployments(namespace).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return false, err
-	}
+		exceptionFound, err := isResourceException(pdb.Name, pdb.Namespace, config.ExceptionPdbs)
+		if err != nil {
+			return nil, err
+		}
 
-	for _, deployment := range deployments.Items {
-		deploymentLabels := labels.Set(deployment.Spec.Template.Labels)
-		if labelSelector.Matches(deploymentLabels) {
-			return true, nil
+		if exceptionFound || pdb.Labels["kor/used"] == "false" {
+			continue
 		}
-	}
 
-	statefulSets, err := clientset.AppsV1().StatefulSets(namespace).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return false, err
-	}
+		// In policy/v1 a null selector selects no pods while an empty one selects every pod of the namespace
+		if pdb.Spec.Selector == nil {
+			continue
+		}
+		labelSelector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil {
+			return nil, err
+		}
 
-	for _, statefulSet := range statefulSets.Items {
-		statefulSetLabels := labels.Set(statefulSet.Spec.Template.Labels)
-		if labelSelector.Matches(statefulSetLabels) {
-			return true, nil
+		if workloads == nil {
+			if workloads, err = retrievePdbWorkloads(clientset, namespace); err != nil {
+				return nil, err
+			}
+		}
+
+		if reason := blockingPdbReason(pdb, workloads.selectedBy(labelSelector)); reason != "" {
+			blockingPdbs = append(blockingPdbs, ResourceInfo{Name: pdb.Name, Reason: reason})
 		}
 	}
 
-	return false, nil
+	return blockingPdbs, nil
 }
 
-func validateMatchingWorkloads(clientset kubernetes.Interface, namespace string, selector *metav1.LabelSelector) (bool, error) {
+func validateRunningPods(clientset kubernetes.Interface, namespace string) (bool, error) {
 	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{
-		LabelSelector: metav1.FormatLabelSelector(selector),
+		FieldSelector: "status.phase=Running",
 	})
 	if err != nil {
 		return false, err
 	}
-	if len(pods.Items) > 0 {
-		return true, nil
+
+	// Field status.phase=Running can still reference Terminating pods
+	// Return true if at least one pod is running
+	for _, pod := range pods.Items {
+		if pod.ObjectMeta.DeletionTimestamp == nil {
+			return true, nil
+		}
 	}
 
 	return false, nil
@@ -168,12 +349,22 @@ func GetUnusedPdbs(filterOpts *filters.Options, clientset kubernetes.Interface,
 				fmt.Fprintf(os.Stderr, "Failed to delete PDB %s in namespace %s: %v\n", diff, namespace, err)
 			}
 		}
+		blocking, err := processNamespaceBlockingPdbs(clientset, namespace, filterOpts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to check blocking PDBs in namespace %s: %v\n", namespace, err)
+		}
 		switch opts.GroupBy {
 		case "namespace":
 			resources[namespace] = make(map[string][]ResourceInfo)
 			resources[namespace]["Pdb"] = diff
+			if len(blocking) > 0 {
+				resources[namespace]["BlockingPdb"] = blocking
+			}
 		case "resource":
 			appendResources(resources, "Pdb", namespace, diff)
+			if len(blocking) > 0 {
+				appendResources(resources, "BlockingPdb", namespace, blocking)
+			}
 		}
 	}
 
@@ -6,8 +6,10 @@ import (
 	"reflect"
 	"testing"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes/fake"
 
 	"github.com/yonahd/kor/pkg/common"
@@ -105,13 +107,54 @@ func createTestPdbs(t *testing.T) *fake.Clientset {
 		t.Fatalf("Error creating fake %s: %v", "Pod", err)
 	}
 
+	// Blocking PDB - selected with matchExpressions only, no pod can ever be unavailable
+	maxUnavailable := intstr.FromInt32(0)
+	pdb8 := CreateTestPdb(testNamespace, "test-pdb8", nil, AppLabels)
+	pdb8.Spec.Selector.MatchExpressions = []v1.LabelSelectorRequirement{
+		{Key: "app", Operator: v1.LabelSelectorOpIn, Values: []string{"my-app"}},
+	}
+	pdb8.Spec.MaxUnavailable = &maxUnavailable
+	_, err = clientset.PolicyV1().PodDisruptionBudgets(testNamespace).Create(context.TODO(), pdb8, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake %s: %v", "Pdb", err)
+	}
+
+	// Blocking PDB - selecting a custom workload managing its pods through a ReplicaSet
+	rolloutLabels := map[string]string{"app": "rollout"}
+	minAvailable := intstr.FromString("100%")
+	pdb9 := CreateTestPdb(testNamespace, "test-pdb9", rolloutLabels, AppLabels)
+	pdb9.Spec.MinAvailable = &minAvailable
+	_, err = clientset.PolicyV1().PodDisruptionBudgets(testNamespace).Create(context.TODO(), pdb9, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake %s: %v", "Pdb", err)
+	}
+
+	// Unused and not blocking - a null selector selects no pods in policy/v1
+	pdb10 := CreateTestPdb(testNamespace, "test-pdb10", nil, AppLabels)
+	pdb10.Spec.Selector = nil
+	pdb10.Spec.MaxUnavailable = &maxUnavailable
+	_, err = clientset.PolicyV1().PodDisruptionBudgets(testNamespace).Create(context.TODO(), pdb10, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake %s: %v", "Pdb", err)
+	}
+
+	controller := true
+	rolloutReplicas := int32(2)
+	replicaSet := CreateTestReplicaSet(testNamespace, "rollout-abc", &rolloutReplicas, &appsv1.ReplicaSetStatus{})
+	replicaSet.Spec.Template.Labels = rolloutLabels
+	replicaSet.OwnerReferences = []v1.OwnerReference{{APIVersion: "argoproj.io/v1alpha1", Kind: "Rollout", Name: "rollout", Controller: &controller}}
+	_, err = clientset.AppsV1().ReplicaSets(testNamespace).Create(context.TODO(), replicaSet, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake %s: %v", "ReplicaSet", err)
+	}
+
 	return clientset
 }
 
 func TestProcessNamespacePdbs(t *testing.T) {
 	clientset := createTestPdbs(t)
 	namespaces := []string{testNamespace, testNamespace2}
-	expectedUnusedPdbs := []string{"test-pdb3", "test-pdb5", "test-pdb7"}
+	expectedUnusedPdbs := []string{"test-pdb10", "test-pdb3", "test-pdb5", "test-pdb7"}
 	totalUnusedPdbs := []ResourceInfo{}
 
 	for _, ns := range namespaces {
@@ -134,6 +177,24 @@ func TestProcessNamespacePdbs(t *testing.T) {
 	}
 }
 
+func TestProcessNamespaceBlockingPdbs(t *testing.T) {
+	clientset := createTestPdbs(t)
+
+	blockingPdbs, err := processNamespaceBlockingPdbs(clientset, testNamespace, &filters.Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expectedPdbs := []ResourceInfo{
+		{Name: "test-pdb8", Reason: "Pdb maxUnavailable of 0 never allows a voluntary eviction of the 3 pods of Deployment/test-deployment2, Pod/test-arbitrary-pod, StatefulSet/test-sts2"},
+		{Name: "test-pdb9", Reason: "Pdb minAvailable of 100% never allows a voluntary eviction of the 2 pods of Rollout/rollout"},
+	}
+
+	if !reflect.DeepEqual(blockingPdbs, expectedPdbs) {
+		t.Errorf("Expected %v, got %v", expectedPdbs, blockingPdbs)
+	}
+}
+
 func TestGetUnusedPdbsStructured(t *testing.T) {
 	clientset := createTestPdbs(t)
 
@@ -154,9 +215,14 @@ func TestGetUnusedPdbsStructured(t *testing.T) {
 	expectedOutput := map[string]map[string][]string{
 		testNamespace: {
 			"Pdb": {
+				"test-pdb10",
 				"test-pdb3",
 				"test-pdb5",
 			},
+			"BlockingPdb": {
+				"test-pdb8",
+				"test-pdb9",
+			},
 		},
 		testNamespace2: {
 			"Pdb": {
@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -15,13 +17,141 @@ import (
 	"github.com/yonahd/kor/pkg/filters"
 )
 
-func processNamespacePods(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
+const evictedPodReason = "Pod is evicted"
+
+func isOwnedByJob(pod corev1.Pod) bool {
+	owner := metav1.GetControllerOf(&pod)
+	return owner != nil && owner.Kind == "Job"
+}
+
+// Since when a pod has been in its current phase, falling back to its creation
+func podPhaseSince(pod corev1.Pod) time.Time {
+	since := pod.CreationTimestamp.Time
+	if pod.Status.Phase == corev1.PodUnknown {
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodReady && condition.LastTransitionTime.After(since) {
+				since = condition.LastTransitionTime.Time
+			}
+		}
+	}
+	return since
+}
+
+// Explain why a pod is unused, or return an empty string
+func podReason(pod corev1.Pod, nodeNames map[string]bool, stuckAfter time.Duration, now time.Time) string {
+	// The deletionTimestamp is set to the end of the grace period
+	if pod.DeletionTimestamp != nil && now.After(pod.DeletionTimestamp.Time) {
+		gracePeriod := int64(0)
+		if pod.DeletionGracePeriodSeconds != nil {
+			gracePeriod = *pod.DeletionGracePeriodSeconds
+		}
+		return fmt.Sprintf("Pod has been terminating since %s, past its grace period of %ds", pod.DeletionTimestamp.UTC().Format(time.RFC3339), gracePeriod)
+	}
+
+	if nodeNames != nil && pod.Spec.NodeName != "" && !nodeNames[pod.Spec.NodeName] {
+		return fmt.Sprintf("Pod is bound to node %s which no longer exists", pod.Spec.NodeName)
+	}
+
+	switch pod.Status.Phase {
+	case corev1.PodFailed:
+		if pod.Status.Reason == "Evicted" {
+			return evictedPodReason
+		}
+		if !isOwnedByJob(pod) {
+			return "Pod has failed and is not owned by a Job"
+		}
+	case corev1.PodSucceeded:
+		if !isOwnedByJob(pod) {
+			return "Pod has completed and is not owned by a Job"
+		}
+	case corev1.PodPending, corev1.PodUnknown:
+		if since := podPhaseSince(pod); stuckAfter > 0 && now.Sub(since) >= stuckAfter {
+			return fmt.Sprintf("Pod has been %s since %s", pod.Status.Phase, since.UTC().Format(time.RFC3339))
+		}
+	}
+
+	return ""
+}
+
+// Resolve the workload owning a pod, following ReplicaSets up to their Deployment
+func podWorkload(pod corev1.Pod, replicaSetOwners map[string]*metav1.OwnerReference) (string, bool) {
+	owner := metav1.GetControllerOf(&pod)
+	if owner == nil {
+		return "", false
+	}
+	if owner.Kind == "ReplicaSet" {
+		if deployment := replicaSetOwners[owner.Name]; deployment != nil {
+			return deployment.Kind + "/" + deployment.Name, true
+		}
+	}
+	return owner.Kind + "/" + owner.Name, true
+}
+
+// Replace evicted pods by one entry per owning workload, returned apart from the remaining pods
+func aggregateEvictedPods(clientset kubernetes.Interface, namespace string, pods []corev1.Pod, diff []ResourceInfo) ([]ResourceInfo, []ResourceInfo, error) {
+	replicaSets, err := clientset.AppsV1().ReplicaSets(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	replicaSetOwners := make(map[string]*metav1.OwnerReference, len(replicaSets.Items))
+	for i := range replicaSets.Items {
+		replicaSetOwners[replicaSets.Items[i].Name] = metav1.GetControllerOf(&replicaSets.Items[i])
+	}
+
+	podsByName := make(map[string]corev1.Pod, len(pods))
+	for _, pod := range pods {
+		podsByName[pod.Name] = pod
+	}
+
+	var remaining, aggregated []ResourceInfo
+	evictedCounts := make(map[string]int)
+	var workloads []string
+	for _, info := range diff {
+		if info.Reason != evictedPodReason {
+			remaining = append(remaining, info)
+			continue
+		}
+		workload, owned := podWorkload(podsByName[info.Name], replicaSetOwners)
+		if !owned {
+			remaining = append(remaining, info)
+			continue
+		}
+		if evictedCounts[workload] == 0 {
+			workloads = append(workloads, workload)
+		}
+		evictedCounts[workload]++
+	}
+
+	for _, workload := range workloads {
+		reason := fmt.Sprintf("%d evicted pods", evictedCounts[workload])
+		aggregated = append(aggregated, ResourceInfo{Name: workload, Reason: reason})
+	}
+	return remaining, aggregated, nil
+}
+
+// Names of the cluster nodes, nil when they cannot be listed so that no pod is reported as bound to a lost node
+func retrieveNodeNames(clientset kubernetes.Interface) map[string]bool {
+	nodes, err := clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to list nodes, skipping pods bound to lost nodes: %v\n", err)
+		return nil
+	}
+	nodeNames := make(map[string]bool, len(nodes.Items))
+	for _, node := range nodes.Items {
+		nodeNames[node.Name] = true
+	}
+	return nodeNames
+}
+
+// processNamespacePods returns the unused pods, and with --aggregate-evicted the workloads whose evicted pods were aggregated
+func processNamespacePods(clientset kubernetes.Interface, namespace string, nodeNames map[string]bool, filterOpts *filters.Options) ([]ResourceInfo, []ResourceInfo, error) {
 	podsList, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	var evictedPods []ResourceInfo
+	var unusedPods []ResourceInfo
+	now := time.Now()
 
 	for _, pod := range podsList.Items {
 		if pass := filters.KorLabelFilter(&pod, &filters.Options{}); pass {
@@ -30,24 +160,31 @@ func processNamespacePods(clientset kubernetes.Interface, namespace string, filt
 
 		if pod.Labels["kor/used"] == "false" {
 			reason := "Marked with unused label"
-			evictedPods = append(evictedPods, ResourceInfo{Name: pod.Name, Reason: reason})
+			unusedPods = append(unusedPods, ResourceInfo{Name: pod.Name, Reason: reason})
 			continue
 		}
 
-		if pod.Status.Phase == corev1.PodFailed && pod.Status.Reason == "Evicted" {
-			reason := "Pod is evicted"
-			evictedPods = append(evictedPods, ResourceInfo{Name: pod.Name, Reason: reason})
+		if reason := podReason(pod, nodeNames, filterOpts.PodStuckAfter, now); reason != "" {
+			unusedPods = append(unusedPods, ResourceInfo{Name: pod.Name, Reason: reason})
 		}
+	}
 
+	if filterOpts.PodAggregateEvicted {
+		return aggregateEvictedPods(clientset, namespace, podsList.Items, unusedPods)
 	}
 
-	return evictedPods, nil
+	return unusedPods, nil, nil
 }
 
 func GetUnusedPods(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	if opts.DeleteFlag && filterOpts.PodAggregateEvicted {
+		return "", errors.New("evicted pods cannot be deleted when aggregated by workload, remove --aggregate-evicted to delete them")
+	}
+
 	resources := make(map[string]map[string][]ResourceInfo)
+	nodeNames := retrieveNodeNames(clientset)
 	for _, namespace := range filterOpts.Namespaces(clientset) {
-		diff, err := processNamespacePods(clientset, namespace, filterOpts)
+		diff, evicted, err := processNamespacePods(clientset, namespace, nodeNames, filterOpts)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
 			continue
@@ -61,8 +198,14 @@ func GetUnusedPods(filterOpts *filters.Options, clientset kubernetes.Interface,
 		case "namespace":
 			resources[namespace] = make(map[string][]ResourceInfo)
 			resources[namespace]["Pod"] = diff
+			if len(evicted) > 0 {
+				resources[namespace]["EvictedPods"] = evicted
+			}
 		case "resource":
 			appendResources(resources, "Pod", namespace, diff)
+			if len(evicted) > 0 {
+				appendResources(resources, "EvictedPods", namespace, evicted)
+			}
 		}
 	}
 
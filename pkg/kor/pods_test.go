@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"reflect"
 	"testing"
+	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -92,23 +94,25 @@ func createTestPods(t *testing.T) *fake.Clientset {
 
 func TestProcessNamespacePods(t *testing.T) {
 	clientset := createTestPods(t)
-	evictedPods, err := processNamespacePods(clientset, testNamespace, &filters.Options{})
+	unusedPods, _, err := processNamespacePods(clientset, testNamespace, retrieveNodeNames(clientset), &filters.Options{})
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
 
-	expectedEvictedPods := []string{
+	expectedUnusedPods := []string{
 		"pod-2",
+		"pod-3",
+		"pod-4",
 		"pod-6",
 	}
 
-	if len(evictedPods) != len(expectedEvictedPods) {
-		t.Errorf("Expected %d evicted pods, got %d", len(expectedEvictedPods), len(evictedPods))
+	if len(unusedPods) != len(expectedUnusedPods) {
+		t.Errorf("Expected %d unused pods, got %d", len(expectedUnusedPods), len(unusedPods))
 	}
 
-	for i, pod := range evictedPods {
-		if pod.Name != expectedEvictedPods[i] {
-			t.Errorf("Expected evicted pod %s, got %s", expectedEvictedPods[i], pod)
+	for i, pod := range unusedPods {
+		if pod.Name != expectedUnusedPods[i] {
+			t.Errorf("Expected unused pod %s, got %s", expectedUnusedPods[i], pod)
 		}
 	}
 }
@@ -134,6 +138,8 @@ func TestGetUnusedPodsStructured(t *testing.T) {
 		testNamespace: {
 			"Pod": {
 				"pod-2",
+				"pod-3",
+				"pod-4",
 				"pod-6",
 			},
 		},
@@ -151,6 +157,142 @@ func TestGetUnusedPodsStructured(t *testing.T) {
 	}
 }
 
+func createTestProblemPods(t *testing.T) *fake.Clientset {
+	clientset := fake.NewSimpleClientset()
+
+	_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), &corev1.Namespace{
+		ObjectMeta: v1.ObjectMeta{Name: testNamespace},
+	}, v1.CreateOptions{})
+
+	if err != nil {
+		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
+	}
+
+	_, err = clientset.CoreV1().Nodes().Create(context.TODO(), &corev1.Node{ObjectMeta: v1.ObjectMeta{Name: "node-1"}}, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake node: %v", err)
+	}
+
+	controller := true
+	deletedAt := v1.Date(2024, time.January, 1, 1, 0, 0, 0, time.UTC)
+	gracePeriod := int64(30)
+
+	jobPod := CreateTestPod(testNamespace, "job-pod", "", nil, AppLabels)
+	jobPod.OwnerReferences = []v1.OwnerReference{{Kind: "Job", Name: "job-1", Controller: &controller}}
+	jobPod.Status = corev1.PodStatus{Phase: corev1.PodFailed, Reason: "BackoffLimitExceeded"}
+
+	pendingPod := CreateTestPod(testNamespace, "pending-pod", "", nil, AppLabels)
+	pendingPod.CreationTimestamp = v1.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	pendingPod.Status = corev1.PodStatus{Phase: corev1.PodPending}
+
+	lostPod := CreateTestPod(testNamespace, "lost-pod", "", nil, AppLabels)
+	lostPod.Spec.NodeName = "lost-node"
+	lostPod.Status = corev1.PodStatus{Phase: corev1.PodRunning}
+
+	terminatingPod := CreateTestPod(testNamespace, "terminating-pod", "", nil, AppLabels)
+	terminatingPod.DeletionTimestamp = &deletedAt
+	terminatingPod.DeletionGracePeriodSeconds = &gracePeriod
+	terminatingPod.Spec.NodeName = "node-1"
+	terminatingPod.Status = corev1.PodStatus{Phase: corev1.PodRunning}
+
+	pods := []*corev1.Pod{jobPod, pendingPod, lostPod, terminatingPod}
+
+	// Evicted pods of the same Deployment, reported once with --aggregate-evicted
+	for _, name := range []string{"web-1", "web-2"} {
+		pod := CreateTestPod(testNamespace, name, "", nil, AppLabels)
+		pod.OwnerReferences = []v1.OwnerReference{{Kind: "ReplicaSet", Name: "web-abc", Controller: &controller}}
+		pod.Status = corev1.PodStatus{Phase: corev1.PodFailed, Reason: "Evicted"}
+		pods = append(pods, pod)
+	}
+
+	for _, pod := range pods {
+		_, err = clientset.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake pod: %v", err)
+		}
+	}
+
+	replicaSet := CreateTestReplicaSet(testNamespace, "web-abc", nil, &appsv1.ReplicaSetStatus{})
+	replicaSet.OwnerReferences = []v1.OwnerReference{{Kind: "Deployment", Name: "web", Controller: &controller}}
+	_, err = clientset.AppsV1().ReplicaSets(testNamespace).Create(context.TODO(), replicaSet, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake replicaset: %v", err)
+	}
+
+	return clientset
+}
+
+func TestProcessNamespaceProblemPods(t *testing.T) {
+	clientset := createTestProblemPods(t)
+	unusedPods, evictedWorkloads, err := processNamespacePods(clientset, testNamespace, retrieveNodeNames(clientset), filters.NewFilterOptions())
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	expectedPods := []ResourceInfo{
+		{Name: "lost-pod", Reason: "Pod is bound to node lost-node which no longer exists"},
+		{Name: "pending-pod", Reason: "Pod has been Pending since 2024-01-01T00:00:00Z"},
+		{Name: "terminating-pod", Reason: "Pod has been terminating since 2024-01-01T01:00:00Z, past its grace period of 30s"},
+		{Name: "web-1", Reason: "Pod is evicted"},
+		{Name: "web-2", Reason: "Pod is evicted"},
+	}
+
+	if !reflect.DeepEqual(unusedPods, expectedPods) {
+		t.Errorf("Expected %v, got %v", expectedPods, unusedPods)
+	}
+	if len(evictedWorkloads) != 0 {
+		t.Errorf("Expected no aggregated workloads without --aggregate-evicted, got %v", evictedWorkloads)
+	}
+}
+
+func TestGetUnusedPodsAggregateEvicted(t *testing.T) {
+	clientset := createTestProblemPods(t)
+
+	opts := common.Opts{
+		WebhookURL:    "",
+		Channel:       "",
+		Token:         "",
+		DeleteFlag:    false,
+		NoInteractive: true,
+		GroupBy:       "namespace",
+	}
+
+	filterOpts := filters.NewFilterOptions()
+	filterOpts.PodAggregateEvicted = true
+
+	output, err := GetUnusedPods(filterOpts, clientset, "json", opts)
+	if err != nil {
+		t.Fatalf("Error calling GetUnusedPods: %v", err)
+	}
+
+	expectedOutput := map[string]map[string][]string{
+		testNamespace: {
+			"Pod": {
+				"lost-pod",
+				"pending-pod",
+				"terminating-pod",
+			},
+			"EvictedPods": {
+				"Deployment/web",
+			},
+		},
+	}
+
+	var actualOutput map[string]map[string][]string
+	if err := json.Unmarshal([]byte(output), &actualOutput); err != nil {
+		t.Fatalf("Error unmarshaling actual output: %v", err)
+	}
+
+	if !reflect.DeepEqual(expectedOutput, actualOutput) {
+		t.Errorf("Expected %v, got %v", expectedOutput, actualOutput)
+	}
+
+	opts.DeleteFlag = true
+	if _, err := GetUnusedPods(filterOpts, clientset, "json", opts); err == nil {
+		t.Error("Expected an error when deleting aggregated evicted pods")
+	}
+}
+
 func init() {
 	scheme.Scheme = runtime.NewScheme()
 	_ = corev1.AddToScheme(scheme.Scheme)
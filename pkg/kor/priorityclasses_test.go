@@ -0,0 +1,117 @@
+package kor
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func createTestPriorityClasses(t *testing.T) *fake.Clientset {
+	clientset := fake.NewSimpleClientset()
+
+	priorityClasses := []*schedulingv1.PriorityClass{
+		CreateTestPriorityClass("pod-priority", 100, false, AppLabels),
+		CreateTestPriorityClass("template-priority", 200, false, AppLabels),
+		CreateTestPriorityClass("unused-priority", 300, false, AppLabels),
+		CreateTestPriorityClass("default-priority", 0, true, AppLabels),
+		CreateTestPriorityClass("system-cluster-critical", 2000000000, false, AppLabels),
+		CreateTestPriorityClass("marked-priority", 400, false, UnusedLabels),
+		CreateTestPriorityClass("kept-priority", 500, false, UsedLabels),
+	}
+
+	for _, priorityClass := range priorityClasses {
+		_, err := clientset.SchedulingV1().PriorityClasses().Create(context.TODO(), priorityClass, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake %s: %v", "PriorityClass", err)
+		}
+	}
+
+	pod := CreateTestPod(testNamespace, "test-pod", "", nil, AppLabels)
+	pod.Spec.PriorityClassName = "pod-priority"
+	_, err := clientset.CoreV1().Pods(testNamespace).Create(context.TODO(), pod, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake %s: %v", "Pod", err)
+	}
+
+	// Scaled to zero, its template still references the PriorityClass
+	deployment := CreateTestDeployment(testNamespace, "test-deployment", 0, AppLabels)
+	deployment.Spec.Template.Spec.PriorityClassName = "template-priority"
+	_, err = clientset.AppsV1().Deployments(testNamespace).Create(context.TODO(), deployment, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake %s: %v", "Deployment", err)
+	}
+
+	return clientset
+}
+
+func TestRetrieveUsedPriorityClasses(t *testing.T) {
+	clientset := createTestPriorityClasses(t)
+
+	usedPriorityClasses, err := retrieveUsedPriorityClasses(clientset)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []string{"pod-priority", "template-priority"}
+	if !reflect.DeepEqual(usedPriorityClasses, expected) {
+		t.Errorf("Expected %v, got %v", expected, usedPriorityClasses)
+	}
+}
+
+func TestProcessPriorityClasses(t *testing.T) {
+	clientset := createTestPriorityClasses(t)
+
+	unusedPriorityClasses, err := processPriorityClasses(clientset, &filters.Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []ResourceInfo{
+		{Name: "marked-priority", Reason: "Marked with unused label"},
+		{Name: "unused-priority", Reason: "PriorityClass is not used by any Pod or workload template"},
+	}
+	if !reflect.DeepEqual(unusedPriorityClasses, expected) {
+		t.Errorf("Expected %v, got %v", expected, unusedPriorityClasses)
+	}
+}
+
+func TestGetUnusedPriorityClassesStructured(t *testing.T) {
+	clientset := createTestPriorityClasses(t)
+
+	opts := common.Opts{
+		WebhookURL:    "",
+		Channel:       "",
+		Token:         "",
+		DeleteFlag:    false,
+		NoInteractive: true,
+		GroupBy:       "namespace",
+	}
+
+	output, err := GetUnusedPriorityClasses(&filters.Options{}, clientset, "json", opts)
+	if err != nil {
+		t.Fatalf("Error calling GetUnusedPriorityClasses: %v", err)
+	}
+
+	expectedOutput := map[string]map[string][]string{
+		"": {
+			"PriorityClass": {"marked-priority", "unused-priority"},
+		},
+	}
+
+	var actualOutput map[string]map[string][]string
+	if err := json.Unmarshal([]byte(output), &actualOutput); err != nil {
+		t.Fatalf("Error unmarshaling actual output: %v", err)
+	}
+
+	if !reflect.DeepEqual(expectedOutput, actualOutput) {
+		t.Errorf("Expected output does not match actual output")
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
@@ -15,6 +17,57 @@ import (
 	"github.com/yonahd/kor/pkg/filters"
 )
 
+func pvDetails(pv corev1.PersistentVolume) string {
+	capacity := "unknown"
+	if storage, ok := pv.Spec.Capacity[corev1.ResourceStorage]; ok {
+		capacity = storage.String()
+	}
+	storageClass := pv.Spec.StorageClassName
+	if storageClass == "" {
+		storageClass = "none"
+	}
+	return fmt.Sprintf("capacity %s, storage class %s", capacity, storageClass)
+}
+
+func formerClaim(pv corev1.PersistentVolume) string {
+	if pv.Spec.ClaimRef == nil {
+		return "unknown"
+	}
+	return pv.Spec.ClaimRef.Namespace + "/" + pv.Spec.ClaimRef.Name
+}
+
+// Explain why the PV is not in use, or return an empty string when it should not be reported
+func pvReason(pv corev1.PersistentVolume, releasedOlderThan time.Duration, now time.Time) string {
+	switch pv.Status.Phase {
+	case corev1.VolumeBound:
+		return ""
+	case corev1.VolumeAvailable:
+		if pv.Spec.ClaimRef != nil {
+			return fmt.Sprintf("PV is Available and reserved for claim %s that does not bind it, %s", formerClaim(pv), pvDetails(pv))
+		}
+		return fmt.Sprintf("PV is Available and has never been claimed, %s", pvDetails(pv))
+	case corev1.VolumeReleased:
+		if pv.Spec.PersistentVolumeReclaimPolicy == corev1.PersistentVolumeReclaimRetain {
+			transition := pv.Status.LastPhaseTransitionTime
+			if transition != nil && now.Sub(transition.Time) < releasedOlderThan {
+				return ""
+			}
+			// Without a known transition time the PV is reported regardless of the threshold, and the reason says so
+			if transition == nil && releasedOlderThan > 0 {
+				return fmt.Sprintf("PV is Released with Retain policy, release time unknown, data kept for former claim %s, %s", formerClaim(pv), pvDetails(pv))
+			}
+			return fmt.Sprintf("PV is Released with Retain policy, data kept for former claim %s, %s", formerClaim(pv), pvDetails(pv))
+		}
+		return fmt.Sprintf("PV is Released but reclaim policy %s did not reclaim it, former claim %s, %s", pv.Spec.PersistentVolumeReclaimPolicy, formerClaim(pv), pvDetails(pv))
+	case corev1.VolumeFailed:
+		if pv.Status.Message != "" {
+			return fmt.Sprintf("PV is Failed: %s, %s", pv.Status.Message, pvDetails(pv))
+		}
+		return fmt.Sprintf("PV is Failed, %s", pvDetails(pv))
+	}
+	return fmt.Sprintf("Persistent Volume is not in use, %s", pvDetails(pv))
+}
+
 func processPvs(clientset kubernetes.Interface, filterOpts *filters.Options) ([]ResourceInfo, error) {
 	pvs, err := clientset.CoreV1().PersistentVolumes().List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
 	if err != nil {
@@ -22,6 +75,8 @@ func processPvs(clientset kubernetes.Interface, filterOpts *filters.Options) ([]
 	}
 
 	var unusedPvs []ResourceInfo
+	now := time.Now()
+	unknownReleaseTime := 0
 
 	for _, pv := range pvs.Items {
 		if pass := filters.KorLabelFilter(&pv, &filters.Options{}); pass {
@@ -34,13 +89,21 @@ func processPvs(clientset kubernetes.Interface, filterOpts *filters.Options) ([]
 			continue
 		}
 
-		if pv.Status.Phase != "Bound" {
-			reason := "Persistent Volume is not in use"
+		if pv.Status.Phase == corev1.VolumeReleased && pv.Spec.PersistentVolumeReclaimPolicy == corev1.PersistentVolumeReclaimRetain && pv.Status.LastPhaseTransitionTime == nil {
+			unknownReleaseTime++
+		}
+
+		if reason := pvReason(pv, filterOpts.PvReleasedOlderThan, now); reason != "" {
 			unusedPvs = append(unusedPvs, ResourceInfo{Name: pv.Name, Reason: reason})
 		}
 
 	}
 
+	// lastPhaseTransitionTime is only set from Kubernetes 1.28
+	if filterOpts.PvReleasedOlderThan > 0 && unknownReleaseTime > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %d Released PVs have no lastPhaseTransitionTime, --released-older-than cannot be applied to them\n", unknownReleaseTime)
+	}
+
 	return unusedPvs, nil
 
 }
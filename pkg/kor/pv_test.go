@@ -5,7 +5,10 @@ import (
 	"encoding/json"
 	"reflect"
 	"testing"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
 
@@ -94,3 +97,47 @@ func TestGetUnusedPvs(t *testing.T) {
 		t.Errorf("Expected output does not match actual output")
 	}
 }
+
+func TestPvReason(t *testing.T) {
+	now := time.Now()
+	claimRef := &corev1.ObjectReference{Namespace: testNamespace, Name: "test-pvc"}
+
+	newPv := func(phase corev1.PersistentVolumePhase, policy corev1.PersistentVolumeReclaimPolicy, claim *corev1.ObjectReference, transition *v1.Time) corev1.PersistentVolume {
+		pv := CreateTestPv("test-pv", string(phase), AppLabels, "test-sc1")
+		pv.Spec.Capacity = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("5Gi")}
+		pv.Spec.PersistentVolumeReclaimPolicy = policy
+		pv.Spec.ClaimRef = claim
+		pv.Status.LastPhaseTransitionTime = transition
+		return *pv
+	}
+
+	tests := []struct {
+		name              string
+		pv                corev1.PersistentVolume
+		releasedOlderThan time.Duration
+		expectedReason    string
+	}{
+		{"Bound", newPv(corev1.VolumeBound, corev1.PersistentVolumeReclaimDelete, claimRef, nil), 0, ""},
+		{"Available", newPv(corev1.VolumeAvailable, corev1.PersistentVolumeReclaimRetain, nil, nil), 0,
+			"PV is Available and has never been claimed, capacity 5Gi, storage class test-sc1"},
+		{"ReleasedRetain", newPv(corev1.VolumeReleased, corev1.PersistentVolumeReclaimRetain, claimRef, nil), 0,
+			"PV is Released with Retain policy, data kept for former claim test-namespace/test-pvc, capacity 5Gi, storage class test-sc1"},
+		{"ReleasedRetainRecently", newPv(corev1.VolumeReleased, corev1.PersistentVolumeReclaimRetain, claimRef, &v1.Time{Time: now.Add(-time.Hour)}), 24 * time.Hour, ""},
+		{"ReleasedRetainLongAgo", newPv(corev1.VolumeReleased, corev1.PersistentVolumeReclaimRetain, claimRef, &v1.Time{Time: now.Add(-48 * time.Hour)}), 24 * time.Hour,
+			"PV is Released with Retain policy, data kept for former claim test-namespace/test-pvc, capacity 5Gi, storage class test-sc1"},
+		{"ReleasedRetainUnknownTime", newPv(corev1.VolumeReleased, corev1.PersistentVolumeReclaimRetain, claimRef, nil), 24 * time.Hour,
+			"PV is Released with Retain policy, release time unknown, data kept for former claim test-namespace/test-pvc, capacity 5Gi, storage class test-sc1"},
+		{"ReleasedDelete", newPv(corev1.VolumeReleased, corev1.PersistentVolumeReclaimDelete, claimRef, nil), 0,
+			"PV is Released but reclaim policy Delete did not reclaim it, former claim test-namespace/test-pvc, capacity 5Gi, storage class test-sc1"},
+		{"Failed", newPv(corev1.VolumeFailed, corev1.PersistentVolumeReclaimRecycle, claimRef, nil), 0,
+			"PV is Failed, capacity 5Gi, storage class test-sc1"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if reason := pvReason(test.pv, test.releasedOlderThan, now); reason != test.expectedReason {
+				t.Errorf("Expected reason %q, got %q", test.expectedReason, reason)
+			}
+		})
+	}
+}
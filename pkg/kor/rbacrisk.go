@@ -0,0 +1,310 @@
+package kor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+
+	v1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/strings/slices"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+// Find the dangerous permissions granted by a role and the highest severity among them
+func dangerousPermissions(roleName string, rules []v1.PolicyRule) (int, []string) {
+	severity := 0
+	var permissions []string
+	grant := func(level int, permission string) {
+		if slices.Contains(permissions, permission) {
+			return
+		}
+		permissions = append(permissions, permission)
+		if level > severity {
+			severity = level
+		}
+	}
+
+	if roleName == "cluster-admin" {
+		grant(severityCritical, "cluster-admin")
+	}
+
+	for _, rule := range rules {
+		if len(rule.Resources) == 0 {
+			continue
+		}
+		allVerbs := slices.Contains(rule.Verbs, "*")
+		allResources := slices.Contains(rule.Resources, "*")
+
+		if allVerbs && allResources && slices.Contains(rule.APIGroups, "*") {
+			grant(severityCritical, "all verbs on all resources")
+		} else if allVerbs {
+			grant(severityHigh, "all verbs on "+strings.Join(rule.Resources, "/"))
+		}
+
+		for _, verb := range []string{"escalate", "bind", "impersonate"} {
+			if allVerbs || slices.Contains(rule.Verbs, verb) {
+				grant(severityHigh, verb)
+			}
+		}
+
+		if allResources || slices.Contains(rule.Resources, "secrets") {
+			for _, verb := range []string{"get", "list"} {
+				if allVerbs || slices.Contains(rule.Verbs, verb) {
+					grant(severityMedium, verb+" secrets")
+				}
+			}
+		}
+	}
+
+	return severity, permissions
+}
+
+// Describe the ServiceAccount subjects that no longer exist or that no Pod runs as
+func staleSubjects(subjects []v1.Subject, bindingNamespace string, serviceAccountNames, podServiceAccounts map[string]bool) []string {
+	var stale []string
+	for _, subject := range filterSubjects(subjects, "ServiceAccount") {
+		namespace := subject.Namespace
+		if namespace == "" {
+			namespace = bindingNamespace
+		}
+		name := namespace + "/" + subject.Name
+		switch {
+		case !serviceAccountNames[name]:
+			stale = append(stale, "non-existing ServiceAccount "+name)
+		case !podServiceAccounts[name]:
+			stale = append(stale, "ServiceAccount "+name+" not used by any Pod")
+		}
+	}
+	return stale
+}
+
+func rbacRiskReason(severity int, permissions, subjects []string) string {
+	return fmt.Sprintf("[%s] Grants %s to %s", severityNames[severity], strings.Join(permissions, ", "), strings.Join(subjects, ", "))
+}
+
+// rbacRisk is a binding finding located by its namespace and kind, so findings can be ranked across namespaces
+type rbacRisk struct {
+	namespace string
+	kind      string
+	severityFinding
+}
+
+// Rank findings by severity first, so the most dangerous bindings of the cluster come first whatever their namespace
+func sortRbacRisks(risks []rbacRisk) {
+	sort.SliceStable(risks, func(i, j int) bool {
+		if risks[i].severity != risks[j].severity {
+			return risks[i].severity > risks[j].severity
+		}
+		if risks[i].namespace != risks[j].namespace {
+			return risks[i].namespace < risks[j].namespace
+		}
+		if risks[i].kind != risks[j].kind {
+			return risks[i].kind < risks[j].kind
+		}
+		return risks[i].info.Name < risks[j].info.Name
+	})
+}
+
+func formatRbacRisks(risks []rbacRisk, opts common.Opts) bytes.Buffer {
+	var output bytes.Buffer
+	if len(risks) == 0 {
+		if opts.Verbose {
+			output.WriteString("No RBAC risks found\n")
+		}
+		return output
+	}
+
+	var buf strings.Builder
+	table := tablewriter.NewWriter(&buf)
+	table.SetColWidth(60)
+	header := []string{"#", "SEVERITY", "NAMESPACE", "RESOURCE TYPE", "RESOURCE NAME"}
+	if opts.ShowReason {
+		header = append(header, "REASON")
+	}
+	table.SetHeader(header)
+	for index, risk := range risks {
+		row := getTableRow(index, severityNames[risk.severity], risk.namespace, risk.kind, risk.info.Name)
+		if opts.ShowReason {
+			row = append(row, risk.info.Reason)
+		}
+		table.Append(row)
+	}
+	table.Render()
+
+	output.WriteString(fmt.Sprintf("RBAC risks ranked by severity:\n%s\n", buf.String()))
+	return output
+}
+
+func retrieveRbacRisks(clientset kubernetes.Interface, namespaces []string, filterOpts *filters.Options) ([]rbacRisk, error) {
+	var risks []rbacRisk
+
+	serviceAccounts, err := clientset.CoreV1().ServiceAccounts(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	serviceAccountNames := make(map[string]bool, len(serviceAccounts.Items))
+	for _, sa := range serviceAccounts.Items {
+		serviceAccountNames[sa.Namespace+"/"+sa.Name] = true
+	}
+
+	pods, err := clientset.CoreV1().Pods(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	podServiceAccounts := make(map[string]bool)
+	for _, pod := range pods.Items {
+		serviceAccountName := pod.Spec.ServiceAccountName
+		if serviceAccountName == "" {
+			serviceAccountName = "default"
+		}
+		podServiceAccounts[pod.Namespace+"/"+serviceAccountName] = true
+	}
+
+	clusterRoles, err := clientset.RbacV1().ClusterRoles().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	clusterRoleRules := make(map[string][]v1.PolicyRule, len(clusterRoles.Items))
+	for _, clusterRole := range clusterRoles.Items {
+		clusterRoleRules[clusterRole.Name] = clusterRole.Rules
+	}
+
+	roleBindingExceptions, err := unmarshalConfig(roleBindingsConfig)
+	if err != nil {
+		return nil, err
+	}
+	clusterRoleBindingExceptions, err := unmarshalConfig(clusterRoleBindingsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterRoleBindings, err := clientset.RbacV1().ClusterRoleBindings().List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, crb := range clusterRoleBindings.Items {
+		if pass, _ := filter.SetObject(&crb).Run(filterOpts); pass {
+			continue
+		}
+		if exceptionFound, err := isResourceException(crb.Name, crb.Namespace, clusterRoleBindingExceptions.ExceptionClusterRoleBindings); err != nil {
+			return nil, err
+		} else if exceptionFound {
+			continue
+		}
+
+		rules, exists := clusterRoleRules[crb.RoleRef.Name]
+		if !exists {
+			continue
+		}
+		severity, permissions := dangerousPermissions(crb.RoleRef.Name, rules)
+		if severity == 0 {
+			continue
+		}
+		if subjects := staleSubjects(crb.Subjects, "", serviceAccountNames, podServiceAccounts); len(subjects) > 0 {
+			risks = append(risks, rbacRisk{"", "ClusterRoleBinding", severityFinding{severity, ResourceInfo{Name: crb.Name, Reason: rbacRiskReason(severity, permissions, subjects)}}})
+		}
+	}
+
+	for _, namespace := range namespaces {
+		roleBindings, err := clientset.RbacV1().RoleBindings(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+		if err != nil {
+			return nil, err
+		}
+		roles, err := clientset.RbacV1().Roles(namespace).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		roleRules := make(map[string][]v1.PolicyRule, len(roles.Items))
+		for _, role := range roles.Items {
+			roleRules[role.Name] = role.Rules
+		}
+
+		for _, rb := range roleBindings.Items {
+			if pass, _ := filter.SetObject(&rb).Run(filterOpts); pass {
+				continue
+			}
+			if exceptionFound, err := isResourceException(rb.Name, rb.Namespace, roleBindingExceptions.ExceptionRoleBindings); err != nil {
+				return nil, err
+			} else if exceptionFound {
+				continue
+			}
+
+			var rules []v1.PolicyRule
+			var exists bool
+			switch rb.RoleRef.Kind {
+			case "Role":
+				rules, exists = roleRules[rb.RoleRef.Name]
+			case "ClusterRole":
+				rules, exists = clusterRoleRules[rb.RoleRef.Name]
+			}
+			if !exists {
+				continue
+			}
+			severity, permissions := dangerousPermissions(rb.RoleRef.Name, rules)
+			if severity == 0 {
+				continue
+			}
+			if subjects := staleSubjects(rb.Subjects, namespace, serviceAccountNames, podServiceAccounts); len(subjects) > 0 {
+				risks = append(risks, rbacRisk{namespace, "RoleBinding", severityFinding{severity, ResourceInfo{Name: rb.Name, Reason: rbacRiskReason(severity, permissions, subjects)}}})
+			}
+		}
+	}
+
+	sortRbacRisks(risks)
+	return risks, nil
+}
+
+func GetRbacRisks(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	if opts.DeleteFlag {
+		fmt.Fprintf(os.Stderr, "Deleting RBAC risk findings is not supported, ignoring --delete flag\n")
+	}
+
+	rbacRisks, err := retrieveRbacRisks(clientset, filterOpts.Namespaces(clientset), filterOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to process RBAC risks: %v\n", err)
+	}
+
+	// Grouping keeps the global ranking within each group
+	resources := make(map[string]map[string][]ResourceInfo)
+	for _, risk := range rbacRisks {
+		switch opts.GroupBy {
+		case "namespace":
+			if resources[risk.namespace] == nil {
+				resources[risk.namespace] = make(map[string][]ResourceInfo)
+			}
+			resources[risk.namespace][risk.kind] = append(resources[risk.namespace][risk.kind], risk.info)
+		case "resource":
+			appendResources(resources, risk.kind, risk.namespace, []ResourceInfo{risk.info})
+		}
+	}
+
+	var outputBuffer bytes.Buffer
+	var jsonResponse []byte
+	switch outputFormat {
+	case "table":
+		outputBuffer = formatRbacRisks(rbacRisks, opts)
+	case "json", "yaml":
+		var err error
+		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
+			return "", err
+		}
+	}
+
+	rbacRiskReport, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
+	if err != nil {
+		fmt.Printf("err: %v\n", err)
+	}
+
+	return rbacRiskReport, nil
+}
@@ -0,0 +1,113 @@
+package kor
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func TestDangerousPermissions(t *testing.T) {
+	tests := []struct {
+		name                string
+		roleName            string
+		rules               []rbacv1.PolicyRule
+		expectedSeverity    int
+		expectedPermissions []string
+	}{
+		{"cluster-admin", "cluster-admin", nil, severityCritical, []string{"cluster-admin"}},
+		{"wildcard", "any", []rbacv1.PolicyRule{{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}}, severityCritical,
+			[]string{"all verbs on all resources", "escalate", "bind", "impersonate", "get secrets", "list secrets"}},
+		{"impersonate", "any", []rbacv1.PolicyRule{{Resources: []string{"users"}, Verbs: []string{"impersonate"}}}, severityHigh, []string{"impersonate"}},
+		{"secrets", "any", []rbacv1.PolicyRule{{Resources: []string{"secrets"}, Verbs: []string{"get", "watch"}}}, severityMedium, []string{"get secrets"}},
+		{"harmless", "any", []rbacv1.PolicyRule{{Resources: []string{"pods"}, Verbs: []string{"get", "list"}}}, 0, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			severity, permissions := dangerousPermissions(test.roleName, test.rules)
+			if severity != test.expectedSeverity || !reflect.DeepEqual(permissions, test.expectedPermissions) {
+				t.Errorf("Expected %d %v, got %d %v", test.expectedSeverity, test.expectedPermissions, severity, permissions)
+			}
+		})
+	}
+}
+
+func TestRetrieveRbacRisks(t *testing.T) {
+	const otherNamespace = "another-namespace"
+	clientset := fake.NewSimpleClientset()
+
+	_, err := clientset.CoreV1().ServiceAccounts(testNamespace).Create(context.TODO(), CreateTestServiceAccount(testNamespace, "idle-sa", AppLabels), v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake %s: %v", "ServiceAccount", err)
+	}
+	_, err = clientset.CoreV1().ServiceAccounts(testNamespace).Create(context.TODO(), CreateTestServiceAccount(testNamespace, "running-sa", AppLabels), v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake %s: %v", "ServiceAccount", err)
+	}
+	pod := CreateTestPod(testNamespace, "pod", "running-sa", nil, AppLabels)
+	_, err = clientset.CoreV1().Pods(testNamespace).Create(context.TODO(), pod, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake %s: %v", "Pod", err)
+	}
+
+	clusterAdmin := CreateTestClusterRole("cluster-admin", AppLabels)
+	_, err = clientset.RbacV1().ClusterRoles().Create(context.TODO(), clusterAdmin, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake %s: %v", "ClusterRole", err)
+	}
+	for _, namespace := range []string{testNamespace, otherNamespace} {
+		secretReader := CreateTestRole(namespace, "secret-reader", AppLabels)
+		secretReader.Rules = []rbacv1.PolicyRule{{Resources: []string{"secrets"}, Verbs: []string{"get", "list"}}}
+		_, err = clientset.RbacV1().Roles(namespace).Create(context.TODO(), secretReader, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake %s: %v", "Role", err)
+		}
+	}
+
+	clusterRoleBindings := []*rbacv1.ClusterRoleBinding{
+		CreateTestClusterRoleBindingRoleRef(testNamespace, "admin-missing-sa", "missing-sa", CreateTestRoleRefForClusterRole("cluster-admin")),
+		CreateTestClusterRoleBindingRoleRef(testNamespace, "admin-running-sa", "running-sa", CreateTestRoleRefForClusterRole("cluster-admin")),
+	}
+	for _, clusterRoleBinding := range clusterRoleBindings {
+		_, err = clientset.RbacV1().ClusterRoleBindings().Create(context.TODO(), clusterRoleBinding, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake %s: %v", "ClusterRoleBinding", err)
+		}
+	}
+
+	roleBindings := []*rbacv1.RoleBinding{
+		CreateTestRoleBinding(testNamespace, "admin-idle-sa", "idle-sa", CreateTestRoleRefForClusterRole("cluster-admin")),
+		CreateTestRoleBinding(testNamespace, "secrets-idle-sa", "idle-sa", CreateTestRoleRef("secret-reader")),
+		CreateTestRoleBinding(testNamespace, "secrets-running-sa", "running-sa", CreateTestRoleRef("secret-reader")),
+		CreateTestRoleBinding(otherNamespace, "secrets-missing-sa", "missing-sa", CreateTestRoleRef("secret-reader")),
+	}
+	for _, roleBinding := range roleBindings {
+		_, err = clientset.RbacV1().RoleBindings(roleBinding.Namespace).Create(context.TODO(), roleBinding, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake %s: %v", "RoleBinding", err)
+		}
+	}
+
+	risks, err := retrieveRbacRisks(clientset, []string{otherNamespace, testNamespace}, &filters.Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The critical findings of every namespace rank before the medium ones
+	expected := []rbacRisk{
+		{"", "ClusterRoleBinding", severityFinding{severityCritical, ResourceInfo{Name: "admin-missing-sa", Reason: "[critical] Grants cluster-admin to non-existing ServiceAccount test-namespace/missing-sa"}}},
+		{testNamespace, "RoleBinding", severityFinding{severityCritical, ResourceInfo{Name: "admin-idle-sa", Reason: "[critical] Grants cluster-admin to ServiceAccount test-namespace/idle-sa not used by any Pod"}}},
+		{otherNamespace, "RoleBinding", severityFinding{severityMedium, ResourceInfo{Name: "secrets-missing-sa", Reason: "[medium] Grants get secrets, list secrets to non-existing ServiceAccount another-namespace/missing-sa"}}},
+		{testNamespace, "RoleBinding", severityFinding{severityMedium, ResourceInfo{Name: "secrets-idle-sa", Reason: "[medium] Grants get secrets, list secrets to ServiceAccount test-namespace/idle-sa not used by any Pod"}}},
+	}
+
+	if !reflect.DeepEqual(risks, expected) {
+		t.Errorf("Expected %v, got %v", expected, risks)
+	}
+}
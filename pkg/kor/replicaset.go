@@ -6,41 +6,177 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
 
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/yonahd/kor/pkg/common"
 	"github.com/yonahd/kor/pkg/filters"
 )
 
-func processNamespaceReplicaSets(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
+// Deployments keep 10 old ReplicaSets when revisionHistoryLimit is not set
+const defaultRevisionHistoryLimit = 10
+
+func replicaSetRevision(replicaSet appsv1.ReplicaSet) int64 {
+	revision, err := strconv.ParseInt(replicaSet.Annotations["deployment.kubernetes.io/revision"], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return revision
+}
+
+// if the replicaSet is specified 0 replica and current available & ready & fullyLabeled replica count is all 0, think the replicaSet is completed
+func isReplicaSetCompleted(replicaSet appsv1.ReplicaSet) bool {
+	return desiredReplicas(replicaSet.Spec.Replicas) == 0 && replicaSet.Status.AvailableReplicas == 0 && replicaSet.Status.ReadyReplicas == 0 && replicaSet.Status.FullyLabeledReplicas == 0
+}
+
+// Rank the completed ReplicaSets of a Deployment from newest to oldest revision, leaving out the current one
+func deploymentHistory(deployment appsv1.Deployment, replicaSets []appsv1.ReplicaSet) []appsv1.ReplicaSet {
+	var owned []appsv1.ReplicaSet
+	for _, replicaSet := range replicaSets {
+		if owner := metav1.GetControllerOf(&replicaSet); owner != nil && owner.UID == deployment.UID {
+			owned = append(owned, replicaSet)
+		}
+	}
+	sort.SliceStable(owned, func(i, j int) bool {
+		if revisionI, revisionJ := replicaSetRevision(owned[i]), replicaSetRevision(owned[j]); revisionI != revisionJ {
+			return revisionI > revisionJ
+		}
+		return owned[j].CreationTimestamp.Before(&owned[i].CreationTimestamp)
+	})
+
+	var history []appsv1.ReplicaSet
+	for i, replicaSet := range owned {
+		if i > 0 && isReplicaSetCompleted(replicaSet) {
+			history = append(history, replicaSet)
+		}
+	}
+	return history
+}
+
+// Check whether the owner of a ReplicaSet still exists, whatever its kind, an owner whose kind is no longer served is gone
+func replicaSetOwnerExists(resolver *scaleTargetResolver, namespace string, owner metav1.OwnerReference) (bool, error) {
+	resource, _, served, err := resolver.resourceFor(owner.APIVersion, owner.Kind)
+	if err != nil {
+		return false, err
+	}
+	if !served || resource == nil {
+		return false, nil
+	}
+
+	gv, err := schema.ParseGroupVersion(owner.APIVersion)
+	if err != nil {
+		return false, err
+	}
+	object, err := resolver.dynamicClient.Resource(gv.WithResource(resource.Name)).Namespace(namespace).Get(context.TODO(), owner.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	// An owner recreated under the same name is a different object
+	return object.GetUID() == owner.UID, nil
+}
+
+func processNamespaceReplicaSets(clientset kubernetes.Interface, dynamicClient dynamic.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
 	replicaSetList, err := clientset.AppsV1().ReplicaSets(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
 	if err != nil {
 		return nil, err
 	}
 
-	var unusedReplicaSetNames []ResourceInfo
+	deploymentList, err := clientset.AppsV1().Deployments(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	deployments := make(map[types.UID]appsv1.Deployment, len(deploymentList.Items))
+	for _, deployment := range deploymentList.Items {
+		deployments[deployment.UID] = deployment
+	}
+
+	var ownerlessReplicaSets, historyReplicaSets []ResourceInfo
+	histories := make(map[types.UID][]appsv1.ReplicaSet)
+	resolver := newScaleTargetResolver(clientset, dynamicClient)
+	existingOwners := make(map[types.UID]bool)
 
 	for _, replicaSet := range replicaSetList.Items {
 		if pass, _ := filter.SetObject(&replicaSet).Run(filterOpts); pass {
 			continue
 		}
 
-		// if the replicaSet is specified 0 replica and current available & ready & fullyLabeled replica count is all 0, think the replicaSet is completed
-		if *replicaSet.Spec.Replicas == 0 && replicaSet.Status.AvailableReplicas == 0 && replicaSet.Status.ReadyReplicas == 0 && replicaSet.Status.FullyLabeledReplicas == 0 {
-			reason := "ReplicaSet is not in use"
-			unusedReplicaSetNames = append(unusedReplicaSetNames, ResourceInfo{Name: replicaSet.Name, Reason: reason})
+		if !isReplicaSetCompleted(replicaSet) {
+			continue
+		}
+
+		owner := metav1.GetControllerOf(&replicaSet)
+		if owner == nil {
+			reason := "ReplicaSet is not in use and has no owner"
+			ownerlessReplicaSets = append(ownerlessReplicaSets, ResourceInfo{Name: replicaSet.Name, Reason: reason})
+			continue
+		}
+
+		// The history policy of other controllers is unknown, only their existence is checked
+		if owner.Kind != "Deployment" {
+			exists, resolved := existingOwners[owner.UID]
+			if !resolved {
+				if exists, err = replicaSetOwnerExists(resolver, namespace, *owner); err != nil {
+					fmt.Fprintf(os.Stderr, "Failed to look up owner %s %s of ReplicaSet %s in namespace %s: %v\n", owner.Kind, owner.Name, replicaSet.Name, namespace, err)
+					continue
+				}
+				existingOwners[owner.UID] = exists
+			}
+			if !exists {
+				reason := fmt.Sprintf("ReplicaSet is not in use and its owner %s %s no longer exists", owner.Kind, owner.Name)
+				ownerlessReplicaSets = append(ownerlessReplicaSets, ResourceInfo{Name: replicaSet.Name, Reason: reason})
+			}
+			continue
+		}
+
+		deployment, exists := deployments[owner.UID]
+		if !exists {
+			reason := fmt.Sprintf("ReplicaSet is not in use and its owner Deployment %s no longer exists", owner.Name)
+			ownerlessReplicaSets = append(ownerlessReplicaSets, ResourceInfo{Name: replicaSet.Name, Reason: reason})
+			continue
+		}
+
+		if _, resolved := histories[deployment.UID]; !resolved {
+			histories[deployment.UID] = deploymentHistory(deployment, replicaSetList.Items)
+		}
+
+		limit := defaultRevisionHistoryLimit
+		if deployment.Spec.RevisionHistoryLimit != nil {
+			limit = int(*deployment.Spec.RevisionHistoryLimit)
+		}
+		for position, old := range histories[deployment.UID] {
+			if old.UID != replicaSet.UID {
+				continue
+			}
+			if position >= limit {
+				reason := fmt.Sprintf("ReplicaSet revision %d exceeds the revisionHistoryLimit of %d of Deployment %s", replicaSetRevision(replicaSet), limit, deployment.Name)
+				historyReplicaSets = append(historyReplicaSets, ResourceInfo{Name: replicaSet.Name, Reason: reason})
+			} else if filterOpts.ReplicaSetIncludeHistory {
+				reason := fmt.Sprintf("ReplicaSet revision %d is kept by Deployment %s for rollback", replicaSetRevision(replicaSet), deployment.Name)
+				historyReplicaSets = append(historyReplicaSets, ResourceInfo{Name: replicaSet.Name, Reason: reason})
+			}
+			break
 		}
 	}
 
-	return unusedReplicaSetNames, nil
+	return append(ownerlessReplicaSets, historyReplicaSets...), nil
 }
 
-func GetUnusedReplicaSets(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+func GetUnusedReplicaSets(filterOpts *filters.Options, clientset kubernetes.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts) (string, error) {
 	resources := make(map[string]map[string][]ResourceInfo)
 	for _, namespace := range filterOpts.Namespaces(clientset) {
-		diff, err := processNamespaceReplicaSets(clientset, namespace, filterOpts)
+		diff, err := processNamespaceReplicaSets(clientset, dynamicClient, namespace, filterOpts)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
 			continue
@@ -10,6 +10,10 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/kubernetes/scheme"
 
@@ -67,7 +71,7 @@ func TestProcessNamespaceReplicaSets(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedReplicaSets(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedReplicaSets(&filters.Options{}, clientset, fakedynamic.NewSimpleDynamicClient(runtime.NewScheme()), "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedReplicaSetsStructured: %v", err)
 	}
@@ -88,6 +92,85 @@ func TestProcessNamespaceReplicaSets(t *testing.T) {
 	}
 }
 
+func TestProcessNamespaceReplicaSetHistory(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*v1.APIResourceList{
+		{
+			GroupVersion: "argoproj.io/v1alpha1",
+			APIResources: []v1.APIResource{{Name: "rollouts", Kind: "Rollout", Namespaced: true}},
+		},
+	}
+
+	rollout := CreateTestUnstructered("Rollout", "argoproj.io/v1alpha1", testNamespace, "rollout")
+	rollout.SetUID("rollout-uid")
+	dynamicClient := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			{Group: "argoproj.io", Version: "v1alpha1", Resource: "rollouts"}: "RolloutList",
+		},
+		rollout,
+	)
+
+	var historyLimit int32 = 2
+	deployment := CreateTestDeployment(testNamespace, "web", 1, AppLabels)
+	deployment.UID = "web-uid"
+	deployment.Spec.RevisionHistoryLimit = &historyLimit
+	_, err := clientset.AppsV1().Deployments(testNamespace).Create(context.TODO(), deployment, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake deployment: %v", err)
+	}
+
+	isController := true
+	createReplicaSet := func(name string, replicas int32, revision string, owner *v1.OwnerReference) {
+		replicaSet := CreateTestReplicaSet(testNamespace, name, &replicas, &appsv1.ReplicaSetStatus{Replicas: replicas, AvailableReplicas: replicas, ReadyReplicas: replicas, FullyLabeledReplicas: replicas})
+		replicaSet.UID = types.UID(name)
+		replicaSet.Annotations = map[string]string{"deployment.kubernetes.io/revision": revision}
+		if owner != nil {
+			replicaSet.OwnerReferences = []v1.OwnerReference{*owner}
+		}
+		_, err := clientset.AppsV1().ReplicaSets(testNamespace).Create(context.TODO(), replicaSet, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake replicaSet: %v", err)
+		}
+	}
+
+	webOwner := &v1.OwnerReference{Kind: "Deployment", Name: "web", UID: "web-uid", Controller: &isController}
+	createReplicaSet("web-5", 1, "5", webOwner)
+	createReplicaSet("web-4", 0, "4", webOwner)
+	createReplicaSet("web-3", 0, "3", webOwner)
+	createReplicaSet("web-2", 0, "2", webOwner)
+	createReplicaSet("web-1", 0, "1", webOwner)
+	createReplicaSet("gone-1", 0, "1", &v1.OwnerReference{Kind: "Deployment", Name: "gone", UID: "gone-uid", Controller: &isController})
+	createReplicaSet("rollout-1", 0, "1", &v1.OwnerReference{APIVersion: "argoproj.io/v1alpha1", Kind: "Rollout", Name: "rollout", UID: "rollout-uid", Controller: &isController})
+	createReplicaSet("gone-rollout-1", 0, "1", &v1.OwnerReference{APIVersion: "argoproj.io/v1alpha1", Kind: "Rollout", Name: "gone-rollout", UID: "gone-rollout-uid", Controller: &isController})
+	createReplicaSet("unserved-1", 0, "1", &v1.OwnerReference{APIVersion: "example.com/v1", Kind: "Widget", Name: "widget", UID: "widget-uid", Controller: &isController})
+	createReplicaSet("standalone", 0, "", nil)
+
+	unusedReplicaSets, err := processNamespaceReplicaSets(clientset, dynamicClient, testNamespace, &filters.Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []ResourceInfo{
+		{Name: "gone-1", Reason: "ReplicaSet is not in use and its owner Deployment gone no longer exists"},
+		{Name: "gone-rollout-1", Reason: "ReplicaSet is not in use and its owner Rollout gone-rollout no longer exists"},
+		{Name: "standalone", Reason: "ReplicaSet is not in use and has no owner"},
+		{Name: "unserved-1", Reason: "ReplicaSet is not in use and its owner Widget widget no longer exists"},
+		{Name: "web-1", Reason: "ReplicaSet revision 1 exceeds the revisionHistoryLimit of 2 of Deployment web"},
+		{Name: "web-2", Reason: "ReplicaSet revision 2 exceeds the revisionHistoryLimit of 2 of Deployment web"},
+	}
+	if !reflect.DeepEqual(unusedReplicaSets, expected) {
+		t.Errorf("Expected %v, got %v", expected, unusedReplicaSets)
+	}
+
+	withHistory, err := processNamespaceReplicaSets(clientset, dynamicClient, testNamespace, &filters.Options{ReplicaSetIncludeHistory: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(withHistory) != len(expected)+2 || !resourceInfoContains(withHistory, "web-3") || !resourceInfoContains(withHistory, "web-4") {
+		t.Errorf("Expected the rollback history to be reported, got %v", withHistory)
+	}
+}
+
 func init() {
 	scheme.Scheme = runtime.NewScheme()
 	_ = appsv1.AddToScheme(scheme.Scheme)
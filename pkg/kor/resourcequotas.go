@@ -0,0 +1,255 @@
+package kor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
+	"k8s.io/utils/strings/slices"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func isBestEffortPod(pod corev1.Pod) bool {
+	for _, containers := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for _, container := range containers {
+			if len(container.Resources.Requests) > 0 || len(container.Resources.Limits) > 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func hasCrossNamespacePodAffinity(pod corev1.Pod) bool {
+	if pod.Spec.Affinity == nil {
+		return false
+	}
+
+	var terms []corev1.PodAffinityTerm
+	if affinity := pod.Spec.Affinity.PodAffinity; affinity != nil {
+		terms = append(terms, affinity.RequiredDuringSchedulingIgnoredDuringExecution...)
+		for _, weighted := range affinity.PreferredDuringSchedulingIgnoredDuringExecution {
+			terms = append(terms, weighted.PodAffinityTerm)
+		}
+	}
+	if antiAffinity := pod.Spec.Affinity.PodAntiAffinity; antiAffinity != nil {
+		terms = append(terms, antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution...)
+		for _, weighted := range antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+			terms = append(terms, weighted.PodAffinityTerm)
+		}
+	}
+
+	for _, term := range terms {
+		if len(term.Namespaces) > 0 || term.NamespaceSelector != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Evaluate a quota scope the way the quota admission plugin does for pods
+func podMatchesQuotaScope(pod corev1.Pod, requirement corev1.ScopedResourceSelectorRequirement) bool {
+	switch requirement.ScopeName {
+	case corev1.ResourceQuotaScopeTerminating:
+		return pod.Spec.ActiveDeadlineSeconds != nil && *pod.Spec.ActiveDeadlineSeconds >= 0
+	case corev1.ResourceQuotaScopeNotTerminating:
+		return pod.Spec.ActiveDeadlineSeconds == nil || *pod.Spec.ActiveDeadlineSeconds < 0
+	case corev1.ResourceQuotaScopeBestEffort:
+		return isBestEffortPod(pod)
+	case corev1.ResourceQuotaScopeNotBestEffort:
+		return !isBestEffortPod(pod)
+	case corev1.ResourceQuotaScopeCrossNamespacePodAffinity:
+		return hasCrossNamespacePodAffinity(pod)
+	case corev1.ResourceQuotaScopePriorityClass:
+		switch requirement.Operator {
+		case corev1.ScopeSelectorOpIn:
+			return slices.Contains(requirement.Values, pod.Spec.PriorityClassName)
+		case corev1.ScopeSelectorOpNotIn:
+			return !slices.Contains(requirement.Values, pod.Spec.PriorityClassName)
+		case corev1.ScopeSelectorOpExists:
+			return pod.Spec.PriorityClassName != ""
+		case corev1.ScopeSelectorOpDoesNotExist:
+			return pod.Spec.PriorityClassName == ""
+		}
+	}
+	return false
+}
+
+// Merge the scopes and the scopeSelector of a quota, a pod must match all of them
+func quotaScopeRequirements(quota corev1.ResourceQuota) []corev1.ScopedResourceSelectorRequirement {
+	var requirements []corev1.ScopedResourceSelectorRequirement
+	for _, scope := range quota.Spec.Scopes {
+		requirements = append(requirements, corev1.ScopedResourceSelectorRequirement{ScopeName: scope, Operator: corev1.ScopeSelectorOpExists})
+	}
+	if quota.Spec.ScopeSelector != nil {
+		requirements = append(requirements, quota.Spec.ScopeSelector.MatchExpressions...)
+	}
+	return requirements
+}
+
+func isAnyPodInQuotaScope(pods []corev1.Pod, requirements []corev1.ScopedResourceSelectorRequirement) bool {
+	for _, pod := range pods {
+		// Pods in a terminal phase are no longer charged to any quota
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		matches := true
+		for _, requirement := range requirements {
+			if !podMatchesQuotaScope(pod, requirement) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+// The quota controller only writes the status when usage changes, so the last status write tells how long usage has been unchanged
+func quotaUsageSince(quota corev1.ResourceQuota) time.Time {
+	since := quota.CreationTimestamp.Time
+	for _, entry := range quota.ManagedFields {
+		if entry.Subresource == "status" && entry.Time != nil && entry.Time.After(since) {
+			since = entry.Time.Time
+		}
+	}
+	return since
+}
+
+// Zero hard limits deliberately forbid resources, a quota made only of them enforces policy even when nothing matches it
+func isDenyQuota(quota corev1.ResourceQuota) bool {
+	if len(quota.Spec.Hard) == 0 {
+		return false
+	}
+	for _, hard := range quota.Spec.Hard {
+		if !hard.IsZero() {
+			return false
+		}
+	}
+	return true
+}
+
+func isQuotaUsageZero(quota corev1.ResourceQuota) bool {
+	if len(quota.Status.Used) == 0 {
+		return false
+	}
+	for _, used := range quota.Status.Used {
+		if !used.IsZero() {
+			return false
+		}
+	}
+	return true
+}
+
+func processNamespaceResourceQuotas(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	quotas, err := clientset.CoreV1().ResourceQuotas(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+	if err != nil {
+		return nil, err
+	}
+	if len(quotas.Items) == 0 {
+		return nil, nil
+	}
+
+	hasWorkloads, err := namespaceHasWorkloads(clientset, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var unusedQuotas []ResourceInfo
+	now := time.Now()
+
+	for _, quota := range quotas.Items {
+		if pass, _ := filter.SetObject(&quota).Run(filterOpts); pass {
+			continue
+		}
+
+		if quota.Labels["kor/used"] == "false" {
+			unusedQuotas = append(unusedQuotas, ResourceInfo{Name: quota.Name, Reason: "Marked with unused label"})
+			continue
+		}
+
+		if !hasWorkloads {
+			unusedQuotas = append(unusedQuotas, ResourceInfo{Name: quota.Name, Reason: "ResourceQuota is in a namespace with no workloads or pods"})
+			continue
+		}
+
+		if isDenyQuota(quota) {
+			continue
+		}
+
+		if requirements := quotaScopeRequirements(quota); len(requirements) > 0 && !isAnyPodInQuotaScope(pods.Items, requirements) {
+			unusedQuotas = append(unusedQuotas, ResourceInfo{Name: quota.Name, Reason: "ResourceQuota scopes match no Pods"})
+			continue
+		}
+
+		if since := quotaUsageSince(quota); isQuotaUsageZero(quota) && now.Sub(since) >= filterOpts.ResourceQuotaIdleAfter {
+			reason := fmt.Sprintf("ResourceQuota tracked resources have all been unused since %s", since.UTC().Format(time.RFC3339))
+			unusedQuotas = append(unusedQuotas, ResourceInfo{Name: quota.Name, Reason: reason})
+		}
+	}
+
+	return unusedQuotas, nil
+}
+
+func GetUnusedResourceQuotas(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	resources := make(map[string]map[string][]ResourceInfo)
+
+	for _, namespace := range filterOpts.Namespaces(clientset) {
+		diff, err := processNamespaceResourceQuotas(clientset, namespace, filterOpts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
+			continue
+		}
+		if opts.DeleteFlag {
+			if diff, err = DeleteResource(diff, clientset, namespace, "ResourceQuota", opts.NoInteractive); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to delete ResourceQuota %s in namespace %s: %v\n", diff, namespace, err)
+			}
+		}
+		switch opts.GroupBy {
+		case "namespace":
+			if diff != nil {
+				resources[namespace] = make(map[string][]ResourceInfo)
+				resources[namespace]["ResourceQuota"] = diff
+			}
+		case "resource":
+			if diff != nil {
+				appendResources(resources, "ResourceQuota", namespace, diff)
+			}
+		}
+	}
+
+	var outputBuffer bytes.Buffer
+	var jsonResponse []byte
+	switch outputFormat {
+	case "table":
+		outputBuffer = FormatOutput(resources, opts)
+	case "json", "yaml":
+		var err error
+		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
+			return "", err
+		}
+	}
+
+	unusedResourceQuotas, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
+	if err != nil {
+		fmt.Printf("err: %v\n", err)
+	}
+
+	return unusedResourceQuotas, nil
+}
@@ -0,0 +1,229 @@
+package kor
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+const emptyNamespace = "empty-namespace"
+
+func createTestResourceQuotas(t *testing.T, statusTime time.Time) *fake.Clientset {
+	clientset := fake.NewSimpleClientset()
+
+	for _, ns := range []string{testNamespace, emptyNamespace} {
+		_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), &corev1.Namespace{
+			ObjectMeta: v1.ObjectMeta{Name: ns},
+		}, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating namespace %s: %v", ns, err)
+		}
+	}
+
+	runningPod := CreateTestPod(testNamespace, "pod-1", "", nil, AppLabels)
+	completedPod := CreateTestPod(testNamespace, "pod-2", "", nil, AppLabels)
+	completedPod.Spec.PriorityClassName = "high"
+	completedPod.Status.Phase = corev1.PodSucceeded
+	for _, pod := range []*corev1.Pod{runningPod, completedPod} {
+		_, err := clientset.CoreV1().Pods(testNamespace).Create(context.TODO(), pod, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake %s: %v", "Pod", err)
+		}
+	}
+
+	pods := corev1.ResourceList{corev1.ResourcePods: resource.MustParse("10")}
+	noPods := corev1.ResourceList{corev1.ResourcePods: resource.MustParse("0")}
+
+	compute := CreateTestResourceQuota(testNamespace, "compute", corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("4")},
+		corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("100m")}, AppLabels)
+
+	bestEffort := CreateTestResourceQuota(testNamespace, "best-effort", pods, nil, AppLabels)
+	bestEffort.Spec.Scopes = []corev1.ResourceQuotaScope{corev1.ResourceQuotaScopeBestEffort}
+
+	terminating := CreateTestResourceQuota(testNamespace, "terminating", pods, nil, AppLabels)
+	terminating.Spec.Scopes = []corev1.ResourceQuotaScope{corev1.ResourceQuotaScopeTerminating}
+
+	highPriority := CreateTestResourceQuota(testNamespace, "high-priority", pods, nil, AppLabels)
+	highPriority.Spec.ScopeSelector = &corev1.ScopeSelector{
+		MatchExpressions: []corev1.ScopedResourceSelectorRequirement{
+			{ScopeName: corev1.ResourceQuotaScopePriorityClass, Operator: corev1.ScopeSelectorOpIn, Values: []string{"high"}},
+		},
+	}
+
+	idle := CreateTestResourceQuota(testNamespace, "idle", pods, noPods, AppLabels)
+	idle.CreationTimestamp = v1.NewTime(statusTime.Add(-10 * 24 * time.Hour))
+	idle.ManagedFields = []v1.ManagedFieldsEntry{
+		{Manager: "kube-controller-manager", Subresource: "status", Time: &v1.Time{Time: statusTime}},
+	}
+
+	recentlyIdle := CreateTestResourceQuota(testNamespace, "recently-idle", pods, noPods, AppLabels)
+	recentlyIdle.CreationTimestamp = v1.NewTime(statusTime.Add(-10 * 24 * time.Hour))
+	recentlyIdle.ManagedFields = []v1.ManagedFieldsEntry{
+		{Manager: "kube-controller-manager", Subresource: "status", Time: &v1.Time{Time: time.Now().Add(-time.Hour)}},
+	}
+
+	noLoadBalancers := CreateTestResourceQuota(testNamespace, "no-loadbalancers", corev1.ResourceList{corev1.ResourceServicesLoadBalancers: resource.MustParse("0")},
+		corev1.ResourceList{corev1.ResourceServicesLoadBalancers: resource.MustParse("0")}, AppLabels)
+	noLoadBalancers.CreationTimestamp = v1.NewTime(statusTime)
+
+	denyBurstable := CreateTestResourceQuota(testNamespace, "deny-burstable", noPods, nil, AppLabels)
+	denyBurstable.Spec.Scopes = []corev1.ResourceQuotaScope{corev1.ResourceQuotaScopeNotBestEffort}
+
+	denyCritical := CreateTestResourceQuota(testNamespace, "deny-critical", noPods, nil, AppLabels)
+	denyCritical.Spec.ScopeSelector = &corev1.ScopeSelector{
+		MatchExpressions: []corev1.ScopedResourceSelectorRequirement{
+			{ScopeName: corev1.ResourceQuotaScopePriorityClass, Operator: corev1.ScopeSelectorOpIn, Values: []string{"system-cluster-critical"}},
+		},
+	}
+
+	// A single zero limit among others does not make a deny quota
+	cappedTerminating := CreateTestResourceQuota(testNamespace, "capped-terminating", corev1.ResourceList{
+		corev1.ResourcePods:                  resource.MustParse("10"),
+		corev1.ResourceServicesLoadBalancers: resource.MustParse("0"),
+	}, nil, AppLabels)
+	cappedTerminating.Spec.Scopes = []corev1.ResourceQuotaScope{corev1.ResourceQuotaScopeTerminating}
+
+	quotas := []*corev1.ResourceQuota{
+		compute,
+		bestEffort,
+		terminating,
+		highPriority,
+		idle,
+		recentlyIdle,
+		noLoadBalancers,
+		denyBurstable,
+		denyCritical,
+		cappedTerminating,
+		CreateTestResourceQuota(testNamespace, "marked", pods, nil, UnusedLabels),
+		CreateTestResourceQuota(emptyNamespace, "template-quota", pods, noPods, AppLabels),
+		CreateTestResourceQuota(emptyNamespace, "deny-all", noPods, noPods, AppLabels),
+	}
+
+	for _, quota := range quotas {
+		_, err := clientset.CoreV1().ResourceQuotas(quota.Namespace).Create(context.TODO(), quota, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake %s: %v", "ResourceQuota", err)
+		}
+	}
+
+	return clientset
+}
+
+func TestProcessNamespaceResourceQuotas(t *testing.T) {
+	statusTime := time.Now().Add(-40 * 24 * time.Hour).Truncate(time.Second)
+	clientset := createTestResourceQuotas(t, statusTime)
+	filterOpts := &filters.Options{ResourceQuotaIdleAfter: filters.DefaultResourceQuotaIdleAfter}
+
+	unusedQuotas, err := processNamespaceResourceQuotas(clientset, testNamespace, filterOpts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []ResourceInfo{
+		{Name: "capped-terminating", Reason: "ResourceQuota scopes match no Pods"},
+		{Name: "high-priority", Reason: "ResourceQuota scopes match no Pods"},
+		{Name: "idle", Reason: "ResourceQuota tracked resources have all been unused since " + statusTime.UTC().Format(time.RFC3339)},
+		{Name: "marked", Reason: "Marked with unused label"},
+		{Name: "terminating", Reason: "ResourceQuota scopes match no Pods"},
+	}
+	if !reflect.DeepEqual(unusedQuotas, expected) {
+		t.Errorf("Expected %v, got %v", expected, unusedQuotas)
+	}
+
+	unusedQuotas, err = processNamespaceResourceQuotas(clientset, emptyNamespace, filterOpts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected = []ResourceInfo{
+		{Name: "deny-all", Reason: "ResourceQuota is in a namespace with no workloads or pods"},
+		{Name: "template-quota", Reason: "ResourceQuota is in a namespace with no workloads or pods"},
+	}
+	if !reflect.DeepEqual(unusedQuotas, expected) {
+		t.Errorf("Expected %v, got %v", expected, unusedQuotas)
+	}
+}
+
+func TestPodMatchesQuotaScope(t *testing.T) {
+	deadline := int64(60)
+	pod := CreateTestPod(testNamespace, "pod", "", nil, AppLabels)
+	pod.Spec.ActiveDeadlineSeconds = &deadline
+	pod.Spec.PriorityClassName = "low"
+	pod.Spec.Containers = []corev1.Container{
+		{Name: "app", Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")}}},
+	}
+	pod.Spec.Affinity = &corev1.Affinity{
+		PodAntiAffinity: &corev1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+				{Weight: 1, PodAffinityTerm: corev1.PodAffinityTerm{TopologyKey: "kubernetes.io/hostname", NamespaceSelector: &v1.LabelSelector{}}},
+			},
+		},
+	}
+
+	tests := []struct {
+		requirement corev1.ScopedResourceSelectorRequirement
+		expected    bool
+	}{
+		{corev1.ScopedResourceSelectorRequirement{ScopeName: corev1.ResourceQuotaScopeTerminating, Operator: corev1.ScopeSelectorOpExists}, true},
+		{corev1.ScopedResourceSelectorRequirement{ScopeName: corev1.ResourceQuotaScopeNotTerminating, Operator: corev1.ScopeSelectorOpExists}, false},
+		{corev1.ScopedResourceSelectorRequirement{ScopeName: corev1.ResourceQuotaScopeBestEffort, Operator: corev1.ScopeSelectorOpExists}, false},
+		{corev1.ScopedResourceSelectorRequirement{ScopeName: corev1.ResourceQuotaScopeNotBestEffort, Operator: corev1.ScopeSelectorOpExists}, true},
+		{corev1.ScopedResourceSelectorRequirement{ScopeName: corev1.ResourceQuotaScopeCrossNamespacePodAffinity, Operator: corev1.ScopeSelectorOpExists}, true},
+		{corev1.ScopedResourceSelectorRequirement{ScopeName: corev1.ResourceQuotaScopePriorityClass, Operator: corev1.ScopeSelectorOpIn, Values: []string{"high", "low"}}, true},
+		{corev1.ScopedResourceSelectorRequirement{ScopeName: corev1.ResourceQuotaScopePriorityClass, Operator: corev1.ScopeSelectorOpNotIn, Values: []string{"low"}}, false},
+		{corev1.ScopedResourceSelectorRequirement{ScopeName: corev1.ResourceQuotaScopePriorityClass, Operator: corev1.ScopeSelectorOpDoesNotExist}, false},
+	}
+
+	for _, test := range tests {
+		if matches := podMatchesQuotaScope(*pod, test.requirement); matches != test.expected {
+			t.Errorf("Expected scope %s %s %v to match %t, got %t", test.requirement.ScopeName, test.requirement.Operator, test.requirement.Values, test.expected, matches)
+		}
+	}
+}
+
+func TestGetUnusedResourceQuotasStructured(t *testing.T) {
+	clientset := createTestResourceQuotas(t, time.Now().Add(-40*24*time.Hour))
+
+	opts := common.Opts{
+		WebhookURL:    "",
+		Channel:       "",
+		Token:         "",
+		DeleteFlag:    false,
+		NoInteractive: true,
+		GroupBy:       "namespace",
+	}
+	filterOpts := &filters.Options{ResourceQuotaIdleAfter: filters.DefaultResourceQuotaIdleAfter}
+
+	output, err := GetUnusedResourceQuotas(filterOpts, clientset, "json", opts)
+	if err != nil {
+		t.Fatalf("Error calling GetUnusedResourceQuotas: %v", err)
+	}
+
+	expectedOutput := map[string]map[string][]string{
+		testNamespace: {
+			"ResourceQuota": {"capped-terminating", "high-priority", "idle", "marked", "terminating"},
+		},
+		emptyNamespace: {
+			"ResourceQuota": {"deny-all", "template-quota"},
+		},
+	}
+
+	var actualOutput map[string]map[string][]string
+	if err := json.Unmarshal([]byte(output), &actualOutput); err != nil {
+		t.Fatalf("Error unmarshaling actual output: %v", err)
+	}
+
+	if !reflect.DeepEqual(expectedOutput, actualOutput) {
+		t.Errorf("Expected output does not match actual output: %v", actualOutput)
+	}
+}
@@ -0,0 +1,122 @@
+package kor
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+//go:embed exceptions/runtimeclasses/runtimeclasses.json
+var runtimeClassesConfig []byte
+
+func retrieveUsedRuntimeClasses(clientset kubernetes.Interface) ([]string, error) {
+	var usedRuntimeClasses []string
+	err := walkPodSpecs(clientset, func(spec corev1.PodSpec) {
+		if spec.RuntimeClassName != nil && *spec.RuntimeClassName != "" {
+			usedRuntimeClasses = append(usedRuntimeClasses, *spec.RuntimeClassName)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return RemoveDuplicatesAndSort(usedRuntimeClasses), nil
+}
+
+func processRuntimeClasses(clientset kubernetes.Interface, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	rcs, err := clientset.NodeV1().RuntimeClasses().List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := unmarshalConfig(runtimeClassesConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var unusedRuntimeClasses []ResourceInfo
+	runtimeClassNames := make([]string, 0, len(rcs.Items))
+
+	for _, rc := range rcs.Items {
+		if pass, _ := filter.SetObject(&rc).Run(filterOpts); pass {
+			continue
+		}
+
+		if rc.Labels["kor/used"] == "false" {
+			unusedRuntimeClasses = append(unusedRuntimeClasses, ResourceInfo{Name: rc.Name, Reason: "Marked with unused label"})
+			continue
+		}
+
+		exceptionFound, err := isResourceException(rc.Name, rc.Namespace, config.ExceptionRuntimeClasses)
+		if err != nil {
+			return nil, err
+		}
+
+		if exceptionFound {
+			continue
+		}
+
+		runtimeClassNames = append(runtimeClassNames, rc.Name)
+	}
+
+	usedRuntimeClasses, err := retrieveUsedRuntimeClasses(clientset)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := CalculateResourceDifference(usedRuntimeClasses, runtimeClassNames)
+	for _, name := range diff {
+		unusedRuntimeClasses = append(unusedRuntimeClasses, ResourceInfo{Name: name, Reason: "RuntimeClass is not used by any Pod or workload template"})
+	}
+	return unusedRuntimeClasses, nil
+}
+
+func GetUnusedRuntimeClasses(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	resources := make(map[string]map[string][]ResourceInfo)
+	diff, err := processRuntimeClasses(clientset, filterOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to process runtimeClasses: %v\n", err)
+	}
+	if opts.DeleteFlag {
+		if diff, err = DeleteResource(diff, clientset, "", "RuntimeClass", opts.NoInteractive); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to delete RuntimeClass %s: %v\n", diff, err)
+		}
+	}
+	switch opts.GroupBy {
+	case "namespace":
+		resources[""] = make(map[string][]ResourceInfo)
+		resources[""]["RuntimeClass"] = diff
+	case "resource":
+		appendResources(resources, "RuntimeClass", "", diff)
+	}
+
+	var outputBuffer bytes.Buffer
+	var jsonResponse []byte
+	switch outputFormat {
+	case "table":
+		outputBuffer = FormatOutput(resources, opts)
+	case "json", "yaml":
+		var err error
+		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
+			return "", err
+		}
+	}
+
+	unusedRuntimeClasses, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
+	if err != nil {
+		fmt.Printf("err: %v\n", err)
+	}
+
+	return unusedRuntimeClasses, nil
+}
@@ -0,0 +1,102 @@
+package kor
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	nodev1 "k8s.io/api/node/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func createTestRuntimeClasses(t *testing.T) *fake.Clientset {
+	clientset := fake.NewSimpleClientset()
+
+	runtimeClasses := []*nodev1.RuntimeClass{
+		CreateTestRuntimeClass("gvisor", "runsc", AppLabels),
+		CreateTestRuntimeClass("kata", "kata", AppLabels),
+		CreateTestRuntimeClass("wasm", "spin", AppLabels),
+		CreateTestRuntimeClass("marked", "runc", UnusedLabels),
+		CreateTestRuntimeClass("nvidia", "nvidia", AppLabels),
+	}
+
+	for _, runtimeClass := range runtimeClasses {
+		_, err := clientset.NodeV1().RuntimeClasses().Create(context.TODO(), runtimeClass, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake %s: %v", "RuntimeClass", err)
+		}
+	}
+
+	gvisor := "gvisor"
+	pod := CreateTestPod(testNamespace, "sandboxed-pod", "", nil, AppLabels)
+	pod.Spec.RuntimeClassName = &gvisor
+	_, err := clientset.CoreV1().Pods(testNamespace).Create(context.TODO(), pod, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake %s: %v", "Pod", err)
+	}
+
+	kata := "kata"
+	statefulSet := CreateTestStatefulSet(testNamespace, "isolated", 0, AppLabels)
+	statefulSet.Spec.Template.Spec.RuntimeClassName = &kata
+	_, err = clientset.AppsV1().StatefulSets(testNamespace).Create(context.TODO(), statefulSet, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake %s: %v", "StatefulSet", err)
+	}
+
+	return clientset
+}
+
+func TestProcessRuntimeClasses(t *testing.T) {
+	clientset := createTestRuntimeClasses(t)
+
+	unusedRuntimeClasses, err := processRuntimeClasses(clientset, &filters.Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []ResourceInfo{
+		{Name: "marked", Reason: "Marked with unused label"},
+		{Name: "wasm", Reason: "RuntimeClass is not used by any Pod or workload template"},
+	}
+	if !reflect.DeepEqual(unusedRuntimeClasses, expected) {
+		t.Errorf("Expected %v, got %v", expected, unusedRuntimeClasses)
+	}
+}
+
+func TestGetUnusedRuntimeClassesStructured(t *testing.T) {
+	clientset := createTestRuntimeClasses(t)
+
+	opts := common.Opts{
+		WebhookURL:    "",
+		Channel:       "",
+		Token:         "",
+		DeleteFlag:    false,
+		NoInteractive: true,
+		GroupBy:       "namespace",
+	}
+
+	output, err := GetUnusedRuntimeClasses(&filters.Options{}, clientset, "json", opts)
+	if err != nil {
+		t.Fatalf("Error calling GetUnusedRuntimeClasses: %v", err)
+	}
+
+	expectedOutput := map[string]map[string][]string{
+		"": {
+			"RuntimeClass": {"marked", "wasm"},
+		},
+	}
+
+	var actualOutput map[string]map[string][]string
+	if err := json.Unmarshal([]byte(output), &actualOutput); err != nil {
+		t.Fatalf("Error unmarshaling actual output: %v", err)
+	}
+
+	if !reflect.DeepEqual(expectedOutput, actualOutput) {
+		t.Errorf("Expected output does not match actual output")
+	}
+}
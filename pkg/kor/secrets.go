@@ -3,11 +3,18 @@ package kor
 import (
 	"bytes"
 	"context"
+	"crypto/x509"
 	_ "embed"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
@@ -17,6 +24,7 @@ import (
 	"github.com/yonahd/kor/pkg/filters"
 )
 
+// Secret types never referenced by Pods, legacy token and Helm release Secrets have their own checks
 var exceptionSecretTypes = []string{
 	`helm.sh/release.v1`,
 	`kubernetes.io/dockerconfigjson`,
@@ -148,6 +156,229 @@ func retrieveSecretNames(clientset kubernetes.Interface, namespace string, filte
 	return names, unusedSecretNames, nil
 }
 
+// Retrieve the legacy ServiceAccount token and Helm release Secrets that pass the filters and exceptions
+func retrieveManagedSecrets(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]corev1.Secret, []corev1.Secret, error) {
+	secrets, err := clientset.CoreV1().Secrets(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	config, err := unmarshalConfig(secretsConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var tokenSecrets, helmSecrets []corev1.Secret
+	for _, secret := range secrets.Items {
+		if secret.Type != corev1.SecretTypeServiceAccountToken && secret.Type != "helm.sh/release.v1" {
+			continue
+		}
+
+		if pass, _ := filter.SetObject(&secret).Run(filterOpts); pass {
+			continue
+		}
+
+		// Already reported by retrieveSecretNames
+		if secret.Labels["kor/used"] == "false" {
+			continue
+		}
+
+		if exceptionFound, err := isResourceException(secret.Name, secret.Namespace, config.ExceptionSecrets); err != nil {
+			return nil, nil, err
+		} else if exceptionFound {
+			continue
+		}
+
+		if secret.Type == corev1.SecretTypeServiceAccountToken {
+			tokenSecrets = append(tokenSecrets, secret)
+		} else {
+			helmSecrets = append(helmSecrets, secret)
+		}
+	}
+	return tokenSecrets, helmSecrets, nil
+}
+
+func processTokenSecrets(tokenSecrets []corev1.Secret, serviceAccountNames []string) []ResourceInfo {
+	var diff []ResourceInfo
+	for _, secret := range tokenSecrets {
+		serviceAccountName := secret.Annotations[corev1.ServiceAccountNameKey]
+		if serviceAccountName == "" || slices.Contains(serviceAccountNames, serviceAccountName) {
+			continue
+		}
+		reason := fmt.Sprintf("ServiceAccount %s of token Secret no longer exists", serviceAccountName)
+		diff = append(diff, ResourceInfo{Name: secret.Name, Reason: reason})
+	}
+	return diff
+}
+
+// helmReleaseRevision is a Helm release Secret with its revision and status labels parsed
+type helmReleaseRevision struct {
+	name     string
+	revision int
+	status   string
+}
+
+func processHelmReleaseSecrets(helmSecrets []corev1.Secret, historyLimit int) []ResourceInfo {
+	releases := make(map[string][]helmReleaseRevision)
+	for _, secret := range helmSecrets {
+		release := secret.Labels["name"]
+		revision, err := strconv.Atoi(secret.Labels["version"])
+		if release == "" || err != nil {
+			continue
+		}
+		releases[release] = append(releases[release], helmReleaseRevision{name: secret.Name, revision: revision, status: secret.Labels["status"]})
+	}
+
+	releaseNames := make([]string, 0, len(releases))
+	for release := range releases {
+		releaseNames = append(releaseNames, release)
+	}
+	sort.Strings(releaseNames)
+
+	var diff []ResourceInfo
+	for _, release := range releaseNames {
+		revisions := releases[release]
+		sort.Slice(revisions, func(i, j int) bool {
+			return revisions[i].revision > revisions[j].revision
+		})
+		latest := revisions[0]
+
+		if latest.status == "uninstalled" {
+			for _, revision := range revisions {
+				reason := fmt.Sprintf("Helm release %s was uninstalled at revision %d", release, latest.revision)
+				diff = append(diff, ResourceInfo{Name: revision.name, Reason: reason})
+			}
+			continue
+		}
+
+		if historyLimit <= 0 || len(revisions) <= historyLimit {
+			continue
+		}
+		for _, revision := range revisions[historyLimit:] {
+			if revision.status == "deployed" {
+				continue
+			}
+			reason := fmt.Sprintf("Helm release %s revision %d is beyond the history limit of %d (latest revision %d)", release, revision.revision, historyLimit, latest.revision)
+			diff = append(diff, ResourceInfo{Name: revision.name, Reason: reason})
+		}
+	}
+	return diff
+}
+
+// tlsCertificateIssue is a kubernetes.io/tls Secret whose certificate expired or expires within the window
+type tlsCertificateIssue struct {
+	secret   string
+	subject  string
+	notAfter time.Time
+	expired  bool
+	reason   string
+}
+
+func certificateSANs(certificate *x509.Certificate) string {
+	sans := append([]string{}, certificate.DNSNames...)
+	for _, ip := range certificate.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	for _, uri := range certificate.URIs {
+		sans = append(sans, uri.String())
+	}
+	sans = append(sans, certificate.EmailAddresses...)
+	if len(sans) == 0 {
+		return "none"
+	}
+	return strings.Join(sans, ", ")
+}
+
+// Parse the leaf certificate of a TLS Secret and check its expiry, returning nil for valid or unparsable certificates
+func checkTlsCertificate(secret corev1.Secret, window time.Duration, now time.Time) *tlsCertificateIssue {
+	block, _ := pem.Decode(secret.Data[corev1.TLSCertKey])
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil
+	}
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil
+	}
+
+	issue := &tlsCertificateIssue{
+		secret:   secret.Name,
+		subject:  certificate.Subject.String(),
+		notAfter: certificate.NotAfter,
+	}
+	details := fmt.Sprintf("subject %s, SANs %s", issue.subject, certificateSANs(certificate))
+	notAfter := certificate.NotAfter.UTC().Format(time.RFC3339)
+
+	switch {
+	case !now.Before(certificate.NotAfter):
+		issue.expired = true
+		issue.reason = fmt.Sprintf("TLS certificate expired on %s, %s", notAfter, details)
+	case window > 0 && certificate.NotAfter.Sub(now) <= window:
+		issue.reason = fmt.Sprintf("TLS certificate expires on %s, within the %s expiry window, %s", notAfter, window, details)
+	default:
+		return nil
+	}
+	return issue
+}
+
+// List the kubernetes.io/tls Secrets of the namespace once, keeping the ones the filters and exceptions let through
+func retrieveTlsSecrets(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]corev1.Secret, error) {
+	secrets, err := clientset.CoreV1().Secrets(namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: filterOpts.IncludeLabels,
+		FieldSelector: "type=" + string(corev1.SecretTypeTLS),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := unmarshalConfig(secretsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var tlsSecrets []corev1.Secret
+	for _, secret := range secrets.Items {
+		if secret.Type != corev1.SecretTypeTLS {
+			continue
+		}
+
+		if pass, _ := filter.SetObject(&secret).Run(filterOpts); pass {
+			continue
+		}
+
+		if exceptionFound, err := isResourceException(secret.Name, secret.Namespace, config.ExceptionSecrets); err != nil {
+			return nil, err
+		} else if exceptionFound {
+			continue
+		}
+
+		tlsSecrets = append(tlsSecrets, secret)
+	}
+	return tlsSecrets, nil
+}
+
+// Secrets holding an expired or soon to expire certificate may still be referenced, they are reported apart from unused ones and never deleted
+func processNamespaceTlsCertificateSecrets(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, []ResourceInfo, error) {
+	tlsSecrets, err := retrieveTlsSecrets(clientset, namespace, filterOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var expired, expiring []ResourceInfo
+	now := time.Now()
+	for _, secret := range tlsSecrets {
+		issue := checkTlsCertificate(secret, filterOpts.CertExpiryWindow, now)
+		switch {
+		case issue == nil:
+			continue
+		case issue.expired:
+			expired = append(expired, ResourceInfo{Name: issue.secret, Reason: issue.reason})
+		default:
+			expiring = append(expiring, ResourceInfo{Name: issue.secret, Reason: issue.reason})
+		}
+	}
+	return expired, expiring, nil
+}
+
 func processNamespaceSecret(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
 	envSecrets, envSecrets2, volumeSecrets, initContainerEnvSecrets, pullSecrets, tlsSecrets, err := retrieveUsedSecret(clientset, namespace)
 	if err != nil {
@@ -192,6 +423,25 @@ func processNamespaceSecret(clientset kubernetes.Interface, namespace string, fi
 		diff = append(diff, ResourceInfo{Name: name, Reason: reason})
 	}
 
+	tokenSecrets, helmSecrets, err := retrieveManagedSecrets(clientset, namespace, filterOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokenSecrets) > 0 {
+		serviceAccounts, err := clientset.CoreV1().ServiceAccounts(namespace).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		serviceAccountNames := make([]string, 0, len(serviceAccounts.Items))
+		for _, sa := range serviceAccounts.Items {
+			serviceAccountNames = append(serviceAccountNames, sa.Name)
+		}
+		diff = append(diff, processTokenSecrets(tokenSecrets, serviceAccountNames)...)
+	}
+
+	diff = append(diff, processHelmReleaseSecrets(helmSecrets, filterOpts.HelmHistoryLimit)...)
+
 	return diff, nil
 
 }
@@ -209,12 +459,28 @@ func GetUnusedSecrets(filterOpts *filters.Options, clientset kubernetes.Interfac
 				fmt.Fprintf(os.Stderr, "Failed to delete Secret %s in namespace %s: %v\n", diff, namespace, err)
 			}
 		}
+		expired, expiring, err := processNamespaceTlsCertificateSecrets(clientset, namespace, filterOpts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to check TLS certificates in namespace %s: %v\n", namespace, err)
+		}
 		switch opts.GroupBy {
 		case "namespace":
 			resources[namespace] = make(map[string][]ResourceInfo)
 			resources[namespace]["Secret"] = diff
+			if len(expired) > 0 {
+				resources[namespace]["ExpiredTlsSecret"] = expired
+			}
+			if len(expiring) > 0 {
+				resources[namespace]["ExpiringTlsSecret"] = expiring
+			}
 		case "resource":
 			appendResources(resources, "Secret", namespace, diff)
+			if len(expired) > 0 {
+				appendResources(resources, "ExpiredTlsSecret", namespace, expired)
+			}
+			if len(expiring) > 0 {
+				appendResources(resources, "ExpiringTlsSecret", namespace, expiring)
+			}
 		}
 	}
 
@@ -2,9 +2,17 @@ package kor
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"encoding/pem"
+	"math/big"
 	"reflect"
 	"testing"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -274,6 +282,171 @@ func TestProcessNamespaceSecret(t *testing.T) {
 
 }
 
+func TestProcessNamespaceManagedSecrets(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+
+	_, err := clientset.CoreV1().ServiceAccounts(testNamespace).Create(context.TODO(), CreateTestServiceAccount(testNamespace, "existing-sa", AppLabels), v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake serviceaccount: %v", err)
+	}
+
+	createTokenSecret := func(name, serviceAccountName string) *corev1.Secret {
+		secret := CreateTestSecret(testNamespace, name, AppLabels)
+		secret.Type = corev1.SecretTypeServiceAccountToken
+		secret.Annotations = map[string]string{corev1.ServiceAccountNameKey: serviceAccountName}
+		return secret
+	}
+	createHelmSecret := func(release string, revision, status string) *corev1.Secret {
+		secret := CreateTestSecret(testNamespace, "sh.helm.release.v1."+release+".v"+revision, map[string]string{
+			"owner":   "helm",
+			"name":    release,
+			"version": revision,
+			"status":  status,
+		})
+		secret.Type = "helm.sh/release.v1"
+		return secret
+	}
+
+	secrets := []*corev1.Secret{
+		createTokenSecret("existing-sa-token", "existing-sa"),
+		createTokenSecret("deleted-sa-token", "deleted-sa"),
+		createHelmSecret("app", "1", "superseded"),
+		createHelmSecret("app", "2", "superseded"),
+		createHelmSecret("app", "10", "deployed"),
+		createHelmSecret("removed", "1", "superseded"),
+		createHelmSecret("removed", "2", "uninstalled"),
+	}
+	for _, secret := range secrets {
+		_, err = clientset.CoreV1().Secrets(testNamespace).Create(context.TODO(), secret, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake secret: %v", err)
+		}
+	}
+
+	filterOpts := &filters.Options{HelmHistoryLimit: 2}
+	unusedSecrets, err := processNamespaceSecret(clientset, testNamespace, filterOpts)
+	if err != nil {
+		t.Fatalf("Error retrieving unused secrets: %v", err)
+	}
+
+	expected := []ResourceInfo{
+		{Name: "deleted-sa-token", Reason: "ServiceAccount deleted-sa of token Secret no longer exists"},
+		{Name: "sh.helm.release.v1.app.v1", Reason: "Helm release app revision 1 is beyond the history limit of 2 (latest revision 10)"},
+		{Name: "sh.helm.release.v1.removed.v2", Reason: "Helm release removed was uninstalled at revision 2"},
+		{Name: "sh.helm.release.v1.removed.v1", Reason: "Helm release removed was uninstalled at revision 2"},
+	}
+
+	if !reflect.DeepEqual(unusedSecrets, expected) {
+		t.Errorf("Expected %v, got %v", expected, unusedSecrets)
+	}
+}
+
+func createTestTlsSecret(t *testing.T, name string, notAfter time.Time) *corev1.Secret {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name + ".example.com"},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Error creating certificate: %v", err)
+	}
+
+	secret := CreateTestSecret(testNamespace, name, AppLabels)
+	secret.Type = corev1.SecretTypeTLS
+	secret.Data = map[string][]byte{
+		corev1.TLSCertKey: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate}),
+	}
+	return secret
+}
+
+func TestTlsCertificateSecrets(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+
+	now := time.Now().Truncate(time.Second)
+	expiredAt := now.Add(-90 * 24 * time.Hour)
+	expiringAt := now.Add(7 * 24 * time.Hour)
+	secrets := []*corev1.Secret{
+		createTestTlsSecret(t, "expired", expiredAt),
+		createTestTlsSecret(t, "expiring", expiringAt),
+		createTestTlsSecret(t, "valid", now.Add(365*24*time.Hour)),
+	}
+	for _, secret := range secrets {
+		_, err := clientset.CoreV1().Secrets(testNamespace).Create(context.TODO(), secret, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake secret: %v", err)
+		}
+	}
+
+	// Every Secret is referenced by an Ingress, so certificate issues are never reported as unused
+	for _, secret := range secrets {
+		ingress := CreateTestIngress(testNamespace, secret.Name, "my-service", secret.Name, AppLabels)
+		_, err := clientset.NetworkingV1().Ingresses(testNamespace).Create(context.TODO(), ingress, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake ingress: %v", err)
+		}
+	}
+
+	filterOpts := &filters.Options{CertExpiryWindow: 30 * 24 * time.Hour}
+
+	unusedSecrets, err := processNamespaceSecret(clientset, testNamespace, filterOpts)
+	if err != nil {
+		t.Fatalf("Error retrieving unused secrets: %v", err)
+	}
+	if len(unusedSecrets) != 0 {
+		t.Errorf("Expected no unused secrets, got %v", unusedSecrets)
+	}
+
+	expiredSecrets, expiringSecrets, err := processNamespaceTlsCertificateSecrets(clientset, testNamespace, filterOpts)
+	if err != nil {
+		t.Fatalf("Error retrieving TLS certificate secrets: %v", err)
+	}
+	expectedExpired := []ResourceInfo{
+		{Name: "expired", Reason: "TLS certificate expired on " + expiredAt.UTC().Format(time.RFC3339) + ", subject CN=expired, SANs expired.example.com"},
+	}
+	if !reflect.DeepEqual(expiredSecrets, expectedExpired) {
+		t.Errorf("Expected %v, got %v", expectedExpired, expiredSecrets)
+	}
+	expectedExpiring := []ResourceInfo{
+		{Name: "expiring", Reason: "TLS certificate expires on " + expiringAt.UTC().Format(time.RFC3339) + ", within the 720h0m0s expiry window, subject CN=expiring, SANs expiring.example.com"},
+	}
+	if !reflect.DeepEqual(expiringSecrets, expectedExpiring) {
+		t.Errorf("Expected %v, got %v", expectedExpiring, expiringSecrets)
+	}
+
+	_, err = clientset.CoreV1().Namespaces().Create(context.TODO(), &corev1.Namespace{
+		ObjectMeta: v1.ObjectMeta{Name: testNamespace},
+	}, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
+	}
+
+	opts := common.Opts{NoInteractive: true, GroupBy: "namespace"}
+	filterOpts.IncludeNamespaces = []string{testNamespace}
+	output, err := GetUnusedSecrets(filterOpts, clientset, "json", opts)
+	if err != nil {
+		t.Fatalf("Error calling GetUnusedSecrets: %v", err)
+	}
+
+	var actualOutput map[string]map[string][]string
+	if err := json.Unmarshal([]byte(output), &actualOutput); err != nil {
+		t.Fatalf("Error unmarshaling actual output: %v", err)
+	}
+	expectedOutput := map[string][]string{
+		"ExpiredTlsSecret":  {"expired"},
+		"ExpiringTlsSecret": {"expiring"},
+	}
+	if !reflect.DeepEqual(actualOutput[testNamespace], expectedOutput) {
+		t.Errorf("Expected %v, got %v", expectedOutput, actualOutput[testNamespace])
+	}
+}
+
 func TestGetUnusedSecretsStructured(t *testing.T) {
 	clientset := createTestSecrets(t)
 
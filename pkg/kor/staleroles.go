@@ -0,0 +1,266 @@
+package kor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	v1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+// servedResources maps every served API group to its resources, across all served versions
+type servedResources map[string]map[string]bool
+
+func newServedResources(resourceLists []*metav1.APIResourceList, failedGroups []string) (servedResources, error) {
+	served := make(servedResources)
+	for _, resourceList := range resourceLists {
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			return nil, err
+		}
+		if served[gv.Group] == nil {
+			served[gv.Group] = make(map[string]bool)
+		}
+		for _, resource := range resourceList.APIResources {
+			served[gv.Group][resource.Name] = true
+		}
+	}
+	// Groups that failed discovery may still be served, so assume all their resources are
+	for _, group := range failedGroups {
+		if served[group] == nil {
+			served[group] = make(map[string]bool)
+		}
+		served[group]["*"] = true
+	}
+	return served, nil
+}
+
+func (s servedResources) serves(group, resource string) bool {
+	if group == "*" {
+		return true
+	}
+	resources, exists := s[group]
+	if !exists {
+		return false
+	}
+	return strings.Contains(resource, "*") || resources["*"] || resources[resource]
+}
+
+// Collect the group/resource references of a rule the server no longer serves
+func staleRuleReferences(rule v1.PolicyRule, served servedResources) ([]string, bool) {
+	var stale []string
+	total := 0
+	for _, group := range rule.APIGroups {
+		for _, resource := range rule.Resources {
+			total++
+			if served.serves(group, resource) {
+				continue
+			}
+			displayGroup := group
+			if displayGroup == "" {
+				displayGroup = "core"
+			}
+			stale = append(stale, displayGroup+"/"+resource)
+		}
+	}
+	return stale, total > 0 && len(stale) == total
+}
+
+// Explain which rules of a role reference unserved API resources, and whether all of them do
+func staleRulesReason(rules []v1.PolicyRule, served servedResources) (string, bool) {
+	var references []string
+	grantingRules, staleRules := 0, 0
+	for _, rule := range rules {
+		// nonResourceURLs are not served through discovery, such rules always still grant access
+		if len(rule.Resources) == 0 {
+			if len(rule.NonResourceURLs) > 0 {
+				grantingRules++
+			}
+			continue
+		}
+		grantingRules++
+		stale, fullyStale := staleRuleReferences(rule, served)
+		if fullyStale {
+			staleRules++
+		}
+		for _, reference := range stale {
+			if !contains(references, reference) {
+				references = append(references, reference)
+			}
+		}
+	}
+
+	if len(references) == 0 {
+		return "", false
+	}
+	if staleRules == grantingRules {
+		return fmt.Sprintf("All rules reference API resources the server no longer serves: %s", strings.Join(references, ", ")), true
+	}
+	return fmt.Sprintf("Some rules reference API resources the server no longer serves: %s", strings.Join(references, ", ")), false
+}
+
+func processStaleClusterRoles(clientset kubernetes.Interface, served servedResources, filterOpts *filters.Options) ([]ResourceInfo, []ResourceInfo, error) {
+	clusterRoles, err := clientset.RbacV1().ClusterRoles().List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	config, err := unmarshalConfig(clusterRolesConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var stale, partiallyStale []ResourceInfo
+	for _, clusterRole := range clusterRoles.Items {
+		if pass, _ := filter.SetObject(&clusterRole).Run(filterOpts); pass {
+			continue
+		}
+		if exceptionFound, err := isResourceException(clusterRole.Name, clusterRole.Namespace, config.ExceptionClusterRoles); err != nil {
+			return nil, nil, err
+		} else if exceptionFound {
+			continue
+		}
+		// Rules of aggregated ClusterRoles are owned by the aggregation controller
+		if clusterRole.AggregationRule != nil && len(clusterRole.AggregationRule.ClusterRoleSelectors) > 0 {
+			continue
+		}
+
+		reason, fullyStale := staleRulesReason(clusterRole.Rules, served)
+		switch {
+		case reason == "":
+		case fullyStale:
+			stale = append(stale, ResourceInfo{Name: clusterRole.Name, Reason: reason})
+		default:
+			partiallyStale = append(partiallyStale, ResourceInfo{Name: clusterRole.Name, Reason: reason})
+		}
+	}
+	return stale, partiallyStale, nil
+}
+
+func processNamespaceStaleRoles(clientset kubernetes.Interface, namespace string, served servedResources, filterOpts *filters.Options) ([]ResourceInfo, []ResourceInfo, error) {
+	roles, err := clientset.RbacV1().Roles(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	config, err := unmarshalConfig(rolesConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var stale, partiallyStale []ResourceInfo
+	for _, role := range roles.Items {
+		if pass, _ := filter.SetObject(&role).Run(filterOpts); pass {
+			continue
+		}
+		if exceptionFound, err := isResourceException(role.Name, role.Namespace, config.ExceptionRoles); err != nil {
+			return nil, nil, err
+		} else if exceptionFound {
+			continue
+		}
+
+		reason, fullyStale := staleRulesReason(role.Rules, served)
+		switch {
+		case reason == "":
+		case fullyStale:
+			stale = append(stale, ResourceInfo{Name: role.Name, Reason: reason})
+		default:
+			partiallyStale = append(partiallyStale, ResourceInfo{Name: role.Name, Reason: reason})
+		}
+	}
+	return stale, partiallyStale, nil
+}
+
+func retrieveServedResources(clientset kubernetes.Interface) (servedResources, error) {
+	_, resourceLists, err := clientset.Discovery().ServerGroupsAndResources()
+	var failedGroups []string
+	if err != nil {
+		var groupDiscoveryFailed *discovery.ErrGroupDiscoveryFailed
+		if !errors.As(err, &groupDiscoveryFailed) {
+			return nil, err
+		}
+		fmt.Fprintf(os.Stderr, "Failed to discover some server resources: %v\n", err)
+		for gv := range groupDiscoveryFailed.Groups {
+			failedGroups = append(failedGroups, gv.Group)
+		}
+	}
+	return newServedResources(resourceLists, failedGroups)
+}
+
+func GetStaleRoles(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	served, err := retrieveServedResources(clientset)
+	if err != nil {
+		return "", err
+	}
+
+	resources := make(map[string]map[string][]ResourceInfo)
+	addResources := func(namespace, resourceType string, diff []ResourceInfo) {
+		switch opts.GroupBy {
+		case "namespace":
+			if resources[namespace] == nil {
+				resources[namespace] = make(map[string][]ResourceInfo)
+			}
+			resources[namespace][resourceType] = diff
+		case "resource":
+			appendResources(resources, resourceType, namespace, diff)
+		}
+	}
+
+	stale, partiallyStale, err := processStaleClusterRoles(clientset, served, filterOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to process cluster roles: %v\n", err)
+	}
+	// Only fully stale roles grant nothing anymore and are safe to delete
+	if opts.DeleteFlag {
+		if stale, err = DeleteResource(stale, clientset, "", "ClusterRole", opts.NoInteractive); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to delete ClusterRole %s: %v\n", stale, err)
+		}
+	}
+	addResources("", "StaleClusterRole", stale)
+	addResources("", "PartiallyStaleClusterRole", partiallyStale)
+
+	for _, namespace := range filterOpts.Namespaces(clientset) {
+		stale, partiallyStale, err := processNamespaceStaleRoles(clientset, namespace, served, filterOpts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
+			continue
+		}
+		if opts.DeleteFlag {
+			if stale, err = DeleteResource(stale, clientset, namespace, "Role", opts.NoInteractive); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to delete Role %s in namespace %s: %v\n", stale, namespace, err)
+			}
+		}
+		addResources(namespace, "StaleRole", stale)
+		addResources(namespace, "PartiallyStaleRole", partiallyStale)
+	}
+
+	var outputBuffer bytes.Buffer
+	var jsonResponse []byte
+	switch outputFormat {
+	case "table":
+		outputBuffer = FormatOutput(resources, opts)
+	case "json", "yaml":
+		var err error
+		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
+			return "", err
+		}
+	}
+
+	staleRoles, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
+	if err != nil {
+		fmt.Printf("err: %v\n", err)
+	}
+
+	return staleRoles, nil
+}
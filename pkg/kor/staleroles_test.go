@@ -0,0 +1,72 @@
+package kor
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func TestProcessStaleRoles(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*v1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []v1.APIResource{{Name: "pods"}, {Name: "secrets"}}},
+		{GroupVersion: "apps/v1", APIResources: []v1.APIResource{{Name: "deployments"}, {Name: "deployments/scale"}}},
+	}
+
+	served, err := retrieveServedResources(clientset)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	podsRule := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}
+	widgetsRule := rbacv1.PolicyRule{APIGroups: []string{"old.example.com"}, Resources: []string{"widgets"}, Verbs: []string{"get"}}
+	mixedRule := rbacv1.PolicyRule{APIGroups: []string{"apps"}, Resources: []string{"deployments/scale", "replicasets"}, Verbs: []string{"get"}}
+	nonResourceRule := rbacv1.PolicyRule{NonResourceURLs: []string{"/healthz"}, Verbs: []string{"get"}}
+
+	roles := map[string][]rbacv1.PolicyRule{
+		"current":       {podsRule, nonResourceRule},
+		"stale":         {widgetsRule},
+		"stale-urls":    {widgetsRule, nonResourceRule},
+		"partial":       {podsRule, widgetsRule},
+		"partial-rule":  {mixedRule},
+		"wildcard":      {{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}},
+		"group-removed": {{APIGroups: []string{"old.example.com"}, Resources: []string{"*"}, Verbs: []string{"*"}}},
+	}
+	for name, rules := range roles {
+		role := CreateTestRole(testNamespace, name, AppLabels)
+		role.Rules = rules
+		_, err := clientset.RbacV1().Roles(testNamespace).Create(context.TODO(), role, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake %s: %v", "Role", err)
+		}
+	}
+
+	stale, partiallyStale, err := processNamespaceStaleRoles(clientset, testNamespace, served, &filters.Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expectedStale := []ResourceInfo{
+		{Name: "group-removed", Reason: "All rules reference API resources the server no longer serves: old.example.com/*"},
+		{Name: "stale", Reason: "All rules reference API resources the server no longer serves: old.example.com/widgets"},
+	}
+	expectedPartiallyStale := []ResourceInfo{
+		{Name: "partial", Reason: "Some rules reference API resources the server no longer serves: old.example.com/widgets"},
+		{Name: "partial-rule", Reason: "Some rules reference API resources the server no longer serves: apps/replicasets"},
+		{Name: "stale-urls", Reason: "Some rules reference API resources the server no longer serves: old.example.com/widgets"},
+	}
+
+	if !reflect.DeepEqual(stale, expectedStale) {
+		t.Errorf("Expected stale roles %v, got %v", expectedStale, stale)
+	}
+	if !reflect.DeepEqual(partiallyStale, expectedPartiallyStale) {
+		t.Errorf("Expected partially stale roles %v, got %v", expectedPartiallyStale, partiallyStale)
+	}
+}
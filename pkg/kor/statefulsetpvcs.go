@@ -0,0 +1,222 @@
+package kor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+// Name of a PVC created from a volumeClaimTemplate: <template>-<statefulset>-<ordinal>
+var statefulSetPvcName = regexp.MustCompile(`^(.+-.+)-(\d+)$`)
+
+func pvcCapacity(pvc corev1.PersistentVolumeClaim) string {
+	if capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok {
+		return capacity.String()
+	}
+	if request, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+		return request.String()
+	}
+	return "unknown"
+}
+
+// Find the StatefulSet and ordinal a PVC was created for from one of its volumeClaimTemplates
+func matchStatefulSetPvc(pvcName string, statefulSets []appsv1.StatefulSet) (*appsv1.StatefulSet, int, bool) {
+	for i := range statefulSets {
+		statefulSet := &statefulSets[i]
+		for _, template := range statefulSet.Spec.VolumeClaimTemplates {
+			prefix := template.Name + "-" + statefulSet.Name + "-"
+			if !strings.HasPrefix(pvcName, prefix) {
+				continue
+			}
+			ordinal, err := strconv.Atoi(strings.TrimPrefix(pvcName, prefix))
+			if err != nil || ordinal < 0 {
+				continue
+			}
+			return statefulSet, ordinal, true
+		}
+	}
+	return nil, 0, false
+}
+
+func statefulSetOrdinalRange(statefulSet *appsv1.StatefulSet) (int, int) {
+	start := 0
+	if statefulSet.Spec.Ordinals != nil {
+		start = int(statefulSet.Spec.Ordinals.Start)
+	}
+	replicas := 1
+	if statefulSet.Spec.Replicas != nil {
+		replicas = int(*statefulSet.Spec.Replicas)
+	}
+	return start, start + replicas
+}
+
+func deletesPvcsWhenScaled(statefulSet *appsv1.StatefulSet) bool {
+	policy := statefulSet.Spec.PersistentVolumeClaimRetentionPolicy
+	return policy != nil && policy.WhenScaled == appsv1.DeletePersistentVolumeClaimRetentionPolicyType
+}
+
+// processNamespaceStatefulSetPvcs returns the leftover PVCs of StatefulSets, and separately the PVCs only inferred from their name to come from one
+func processNamespaceStatefulSetPvcs(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, []ResourceInfo, error) {
+	pvcs, err := clientset.CoreV1().PersistentVolumeClaims(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	statefulSets, err := clientset.AppsV1().StatefulSets(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	usedPvcs, err := retrieveUsedPvcs(clientset, namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	statefulSetUIDs := make(map[types.UID]bool, len(statefulSets.Items))
+	// Name prefixes of the PVCs the StatefulSets of the namespace create
+	claimPrefixes := make(map[string]bool)
+	for _, statefulSet := range statefulSets.Items {
+		statefulSetUIDs[statefulSet.UID] = true
+		for _, template := range statefulSet.Spec.VolumeClaimTemplates {
+			claimPrefixes[template.Name+"-"+statefulSet.Name] = true
+		}
+	}
+
+	var leftoverPvcs, inferredPvcs []ResourceInfo
+	// PVCs without owner that look like they were created by a StatefulSet, grouped by name prefix
+	candidates := make(map[string][]corev1.PersistentVolumeClaim)
+	candidateOrdinals := make(map[string][]int)
+
+	for _, pvc := range pvcs.Items {
+		if pass, _ := filter.SetObject(&pvc).Run(filterOpts); pass {
+			continue
+		}
+
+		if contains(usedPvcs, pvc.Name) {
+			continue
+		}
+
+		if statefulSet, ordinal, ok := matchStatefulSetPvc(pvc.Name, statefulSets.Items); ok {
+			start, end := statefulSetOrdinalRange(statefulSet)
+			if ordinal >= start && ordinal < end {
+				continue
+			}
+			// The StatefulSet controller removes these PVCs itself
+			if deletesPvcsWhenScaled(statefulSet) {
+				continue
+			}
+			reason := fmt.Sprintf("PVC of StatefulSet %s is above current replicas (ordinal %d, replicas %d), capacity %s", statefulSet.Name, ordinal, end-start, pvcCapacity(pvc))
+			leftoverPvcs = append(leftoverPvcs, ResourceInfo{Name: pvc.Name, Reason: reason})
+			continue
+		}
+
+		if len(pvc.OwnerReferences) > 0 {
+			for _, owner := range pvc.OwnerReferences {
+				if owner.Kind == "StatefulSet" && !statefulSetUIDs[owner.UID] {
+					reason := fmt.Sprintf("Owner StatefulSet %s is gone, capacity %s", owner.Name, pvcCapacity(pvc))
+					leftoverPvcs = append(leftoverPvcs, ResourceInfo{Name: pvc.Name, Reason: reason})
+					break
+				}
+			}
+			continue
+		}
+
+		if match := statefulSetPvcName.FindStringSubmatch(pvc.Name); match != nil {
+			ordinal, err := strconv.Atoi(match[2])
+			if err != nil {
+				continue
+			}
+			candidates[match[1]] = append(candidates[match[1]], pvc)
+			candidateOrdinals[match[1]] = append(candidateOrdinals[match[1]], ordinal)
+		}
+	}
+
+	// Without an owner the StatefulSet origin can only be inferred: a group needs its first ordinal, and it is never deleted
+	prefixes := make([]string, 0, len(candidates))
+	for prefix := range candidates {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+
+	for _, prefix := range prefixes {
+		hasFirstOrdinal := false
+		for _, ordinal := range candidateOrdinals[prefix] {
+			if ordinal == 0 {
+				hasFirstOrdinal = true
+				break
+			}
+		}
+		if !hasFirstOrdinal || claimPrefixes[prefix] {
+			continue
+		}
+		for _, pvc := range candidates[prefix] {
+			reason := fmt.Sprintf("PVC name matches the StatefulSet claim pattern %s-<ordinal>, no StatefulSet in the namespace creates it (inferred from the name), capacity %s", prefix, pvcCapacity(pvc))
+			inferredPvcs = append(inferredPvcs, ResourceInfo{Name: pvc.Name, Reason: reason})
+		}
+	}
+
+	return leftoverPvcs, inferredPvcs, nil
+}
+
+func GetUnusedStatefulSetPvcs(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	resources := make(map[string]map[string][]ResourceInfo)
+	for _, namespace := range filterOpts.Namespaces(clientset) {
+		diff, inferred, err := processNamespaceStatefulSetPvcs(clientset, namespace, filterOpts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
+			continue
+		}
+		if opts.DeleteFlag {
+			if diff, err = DeleteResource(diff, clientset, namespace, "StatefulSetPvc", opts.NoInteractive); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to delete PVC %s in namespace %s: %v\n", diff, namespace, err)
+			}
+		}
+		switch opts.GroupBy {
+		case "namespace":
+			resources[namespace] = make(map[string][]ResourceInfo)
+			resources[namespace]["StatefulSetPvc"] = diff
+			if len(inferred) > 0 {
+				resources[namespace]["InferredStatefulSetPvc"] = inferred
+			}
+		case "resource":
+			appendResources(resources, "StatefulSetPvc", namespace, diff)
+			if len(inferred) > 0 {
+				appendResources(resources, "InferredStatefulSetPvc", namespace, inferred)
+			}
+		}
+	}
+
+	var outputBuffer bytes.Buffer
+	var jsonResponse []byte
+	switch outputFormat {
+	case "table":
+		outputBuffer = FormatOutput(resources, opts)
+	case "json", "yaml":
+		var err error
+		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
+			return "", err
+		}
+	}
+
+	unusedStatefulSetPvcs, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
+	if err != nil {
+		fmt.Printf("err: %v\n", err)
+	}
+
+	return unusedStatefulSetPvcs, nil
+}
@@ -0,0 +1,142 @@
+package kor
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func createTestStatefulSetPvcs(t *testing.T) *fake.Clientset {
+	clientset := fake.NewSimpleClientset()
+
+	_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), &corev1.Namespace{
+		ObjectMeta: v1.ObjectMeta{Name: testNamespace},
+	}, v1.CreateOptions{})
+
+	if err != nil {
+		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
+	}
+
+	web := CreateTestStatefulSet(testNamespace, "web", 2, AppLabels)
+	web.Spec.VolumeClaimTemplates = []corev1.PersistentVolumeClaim{{ObjectMeta: v1.ObjectMeta{Name: "data"}}}
+
+	db := CreateTestStatefulSet(testNamespace, "db", 1, AppLabels)
+	db.Spec.VolumeClaimTemplates = []corev1.PersistentVolumeClaim{{ObjectMeta: v1.ObjectMeta{Name: "data"}}}
+	db.Spec.PersistentVolumeClaimRetentionPolicy = &appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy{
+		WhenScaled:  appsv1.DeletePersistentVolumeClaimRetentionPolicyType,
+		WhenDeleted: appsv1.RetainPersistentVolumeClaimRetentionPolicyType,
+	}
+
+	for _, statefulSet := range []*appsv1.StatefulSet{web, db} {
+		_, err = clientset.AppsV1().StatefulSets(testNamespace).Create(context.TODO(), statefulSet, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake statefulset: %v", err)
+		}
+	}
+
+	pvcNames := []string{"data-web-0", "data-web-1", "data-web-2", "data-db-1", "cache-old-0", "cache-old-1", "data-gone-0", "scratch-5", "logs-app-3"}
+	for _, name := range pvcNames {
+		pvc := CreateTestPvc(testNamespace, name, AppLabels, "test-sc")
+		switch name {
+		case "data-web-2":
+			pvc.Status.Capacity = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")}
+		case "data-gone-0":
+			pvc.OwnerReferences = []v1.OwnerReference{{Kind: "StatefulSet", Name: "gone", UID: "gone-uid"}}
+		}
+		_, err = clientset.CoreV1().PersistentVolumeClaims(testNamespace).Create(context.TODO(), pvc, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake pvc: %v", err)
+		}
+	}
+
+	usedPvc := CreateTestPvc(testNamespace, "data-web-5", UsedLabels, "test-sc")
+	_, err = clientset.CoreV1().PersistentVolumeClaims(testNamespace).Create(context.TODO(), usedPvc, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake pvc: %v", err)
+	}
+
+	pod := CreateTestPod(testNamespace, "web-0", "", []corev1.Volume{*CreateTestVolume("data", "data-web-0")}, AppLabels)
+	_, err = clientset.CoreV1().Pods(testNamespace).Create(context.TODO(), pod, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake pod: %v", err)
+	}
+
+	return clientset
+}
+
+func TestProcessNamespaceStatefulSetPvcs(t *testing.T) {
+	clientset := createTestStatefulSetPvcs(t)
+
+	leftoverPvcs, inferredPvcs, err := processNamespaceStatefulSetPvcs(clientset, testNamespace, &filters.Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expectedPvcs := []ResourceInfo{
+		{Name: "data-gone-0", Reason: "Owner StatefulSet gone is gone, capacity unknown"},
+		{Name: "data-web-2", Reason: "PVC of StatefulSet web is above current replicas (ordinal 2, replicas 2), capacity 10Gi"},
+	}
+
+	if !reflect.DeepEqual(leftoverPvcs, expectedPvcs) {
+		t.Errorf("Expected %v, got %v", expectedPvcs, leftoverPvcs)
+	}
+
+	expectedInferredPvcs := []ResourceInfo{
+		{Name: "cache-old-0", Reason: "PVC name matches the StatefulSet claim pattern cache-old-<ordinal>, no StatefulSet in the namespace creates it (inferred from the name), capacity unknown"},
+		{Name: "cache-old-1", Reason: "PVC name matches the StatefulSet claim pattern cache-old-<ordinal>, no StatefulSet in the namespace creates it (inferred from the name), capacity unknown"},
+	}
+
+	if !reflect.DeepEqual(inferredPvcs, expectedInferredPvcs) {
+		t.Errorf("Expected %v, got %v", expectedInferredPvcs, inferredPvcs)
+	}
+}
+
+func TestGetUnusedStatefulSetPvcsStructured(t *testing.T) {
+	clientset := createTestStatefulSetPvcs(t)
+
+	opts := common.Opts{
+		WebhookURL:    "",
+		Channel:       "",
+		Token:         "",
+		DeleteFlag:    false,
+		NoInteractive: true,
+		GroupBy:       "namespace",
+	}
+
+	output, err := GetUnusedStatefulSetPvcs(&filters.Options{}, clientset, "json", opts)
+	if err != nil {
+		t.Fatalf("Error calling GetUnusedStatefulSetPvcsStructured: %v", err)
+	}
+
+	expectedOutput := map[string]map[string][]string{
+		testNamespace: {
+			"StatefulSetPvc": {
+				"data-gone-0",
+				"data-web-2",
+			},
+			"InferredStatefulSetPvc": {
+				"cache-old-0",
+				"cache-old-1",
+			},
+		},
+	}
+
+	var actualOutput map[string]map[string][]string
+	if err := json.Unmarshal([]byte(output), &actualOutput); err != nil {
+		t.Fatalf("Error unmarshaling actual output: %v", err)
+	}
+
+	if !reflect.DeepEqual(expectedOutput, actualOutput) {
+		t.Errorf("Expected output does not match actual output")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -35,7 +36,7 @@ func processNamespaceStatefulSets(clientset kubernetes.Interface, namespace stri
 			continue
 		}
 
-		if *statefulSet.Spec.Replicas == 0 {
+		if desiredReplicas(statefulSet.Spec.Replicas) == 0 {
 			status.Reason = "StatefulSet has no replicas"
 			statefulSetsWithoutReplicas = append(statefulSetsWithoutReplicas, status)
 		}
@@ -44,6 +45,41 @@ func processNamespaceStatefulSets(clientset kubernetes.Interface, namespace stri
 	return statefulSetsWithoutReplicas, nil
 }
 
+// Broken StatefulSets still want replicas, they are reported apart from unused ones and never deleted
+func processNamespaceBrokenStatefulSets(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	if !filterOpts.IncludeBroken {
+		return nil, nil
+	}
+
+	statefulSetsList, err := clientset.AppsV1().StatefulSets(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := retrieveBrokenWorkloadPods(clientset, namespace, filterOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	var brokenStatefulSets []ResourceInfo
+
+	for _, statefulSet := range statefulSetsList.Items {
+		if pass, _ := filter.SetObject(&statefulSet).Run(filterOpts); pass {
+			continue
+		}
+
+		if statefulSet.Labels["kor/used"] == "false" || desiredReplicas(statefulSet.Spec.Replicas) == 0 {
+			continue
+		}
+
+		if reason := brokenStatefulSetReason(statefulSet, pods, filterOpts.BrokenAfter, time.Now()); reason != "" {
+			brokenStatefulSets = append(brokenStatefulSets, ResourceInfo{Name: statefulSet.Name, Reason: reason})
+		}
+	}
+
+	return brokenStatefulSets, nil
+}
+
 func GetUnusedStatefulSets(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
 	resources := make(map[string]map[string][]ResourceInfo)
 	for _, namespace := range filterOpts.Namespaces(clientset) {
@@ -57,16 +93,28 @@ func GetUnusedStatefulSets(filterOpts *filters.Options, clientset kubernetes.Int
 				fmt.Fprintf(os.Stderr, "Failed to delete Statefulset %s in namespace %s: %v\n", diff, namespace, err)
 			}
 		}
+		broken, err := processNamespaceBrokenStatefulSets(clientset, namespace, filterOpts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to check broken StatefulSets in namespace %s: %v\n", namespace, err)
+		}
 		switch opts.GroupBy {
 		case "namespace":
-			if diff != nil {
+			if diff != nil || broken != nil {
 				resources[namespace] = make(map[string][]ResourceInfo)
+			}
+			if diff != nil {
 				resources[namespace]["StatefulSet"] = diff
 			}
+			if broken != nil {
+				resources[namespace]["BrokenStatefulSet"] = broken
+			}
 		case "resource":
 			if diff != nil {
 				appendResources(resources, "StatefulSet", namespace, diff)
 			}
+			if broken != nil {
+				appendResources(resources, "BrokenStatefulSet", namespace, broken)
+			}
 		}
 	}
 
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"reflect"
 	"testing"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -72,6 +73,43 @@ func TestProcessNamespaceStatefulSets(t *testing.T) {
 	}
 }
 
+func TestProcessNamespaceBrokenStatefulSets(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	longAgo := v1.NewTime(time.Now().Add(-30 * 24 * time.Hour))
+
+	for _, name := range []string{"running", "unavailable"} {
+		statefulSet := CreateTestStatefulSet(testNamespace, name, 1, map[string]string{"app": name})
+		statefulSet.Spec.Selector = &v1.LabelSelector{MatchLabels: map[string]string{"app": name}}
+		statefulSet.CreationTimestamp = longAgo
+		if name == "running" {
+			statefulSet.Status.AvailableReplicas = 1
+		}
+		_, err := clientset.AppsV1().StatefulSets(testNamespace).Create(context.TODO(), statefulSet, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake statefulset: %v", err)
+		}
+	}
+
+	pod := CreateTestPod(testNamespace, "unavailable-0", "", nil, map[string]string{"app": "unavailable"})
+	pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionFalse, LastTransitionTime: longAgo}}
+	_, err := clientset.CoreV1().Pods(testNamespace).Create(context.TODO(), pod, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake pod: %v", err)
+	}
+
+	brokenStatefulSets, err := processNamespaceBrokenStatefulSets(clientset, testNamespace, &filters.Options{IncludeBroken: true, BrokenAfter: time.Hour})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []ResourceInfo{
+		{Name: "unavailable", Reason: "StatefulSet has had no available replicas since " + longAgo.UTC().Format(time.RFC3339)},
+	}
+	if !reflect.DeepEqual(brokenStatefulSets, expected) {
+		t.Errorf("Expected %v, got %v", expected, brokenStatefulSets)
+	}
+}
+
 func TestGetUnusedStatefulSetsStructured(t *testing.T) {
 	clientset := createTestStatefulSets(t)
 
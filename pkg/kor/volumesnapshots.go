@@ -0,0 +1,328 @@
+package kor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+var (
+	volumeSnapshotGVR        = schema.GroupVersionResource{Group: "snapshot.storage.k8s.io", Version: "v1", Resource: "volumesnapshots"}
+	volumeSnapshotContentGVR = schema.GroupVersionResource{Group: "snapshot.storage.k8s.io", Version: "v1", Resource: "volumesnapshotcontents"}
+	volumeSnapshotClassGVR   = schema.GroupVersionResource{Group: "snapshot.storage.k8s.io", Version: "v1", Resource: "volumesnapshotclasses"}
+)
+
+// Snapshot kinds are deleted through the dynamic client rather than DeleteResource
+var volumeSnapshotKindGVRs = map[string]schema.GroupVersionResource{
+	"VolumeSnapshot":        volumeSnapshotGVR,
+	"VolumeSnapshotContent": volumeSnapshotContentGVR,
+	"VolumeSnapshotClass":   volumeSnapshotClassGVR,
+}
+
+// Annotation marking the VolumeSnapshotClass used by VolumeSnapshots created without a class
+const defaultVolumeSnapshotClassAnnotation = "snapshot.storage.kubernetes.io/is-default-class"
+
+// The snapshot CRDs are only installed along with the external snapshot controller
+func isVolumeSnapshotServed(clientset kubernetes.Interface) (bool, error) {
+	_, err := clientset.Discovery().ServerResourcesForGroupVersion(volumeSnapshotGVR.GroupVersion().String())
+	if errors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func volumeSnapshotRestoreSize(snapshot unstructured.Unstructured) string {
+	if size, found, _ := unstructured.NestedString(snapshot.Object, "status", "restoreSize"); found && size != "" {
+		return size
+	}
+	return "unknown"
+}
+
+// VolumeSnapshotContents report their restore size in bytes
+func volumeSnapshotContentRestoreSize(content unstructured.Unstructured) string {
+	if size, found, _ := unstructured.NestedInt64(content.Object, "status", "restoreSize"); found {
+		return resource.NewQuantity(size, resource.BinarySI).String()
+	}
+	return "unknown"
+}
+
+func processNamespaceVolumeSnapshots(clientset kubernetes.Interface, dynamicClient dynamic.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	snapshots, err := dynamicClient.Resource(volumeSnapshotGVR).Namespace(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+	if err != nil {
+		return nil, err
+	}
+
+	pvcs, err := clientset.CoreV1().PersistentVolumeClaims(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	pvcNames := make(map[string]bool, len(pvcs.Items))
+	for _, pvc := range pvcs.Items {
+		pvcNames[pvc.Name] = true
+	}
+
+	var unusedSnapshots []ResourceInfo
+	now := time.Now()
+
+	for _, snapshot := range snapshots.Items {
+		if pass, _ := filter.SetObject(&snapshot).Run(filterOpts); pass {
+			continue
+		}
+
+		if snapshot.GetLabels()["kor/used"] == "false" {
+			unusedSnapshots = append(unusedSnapshots, ResourceInfo{Name: snapshot.GetName(), Reason: "Marked with unused label"})
+			continue
+		}
+
+		// Pre-provisioned snapshots are imported from an existing VolumeSnapshotContent and have no source PVC
+		pvcName, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+		if pvcName == "" || pvcNames[pvcName] {
+			continue
+		}
+
+		// Recent snapshots of a deleted PVC are often kept on purpose as a backup
+		if now.Sub(snapshot.GetCreationTimestamp().Time) < filterOpts.VolumeSnapshotOlderThan {
+			continue
+		}
+
+		reason := fmt.Sprintf("VolumeSnapshot source PVC %s no longer exists, restore size %s", pvcName, volumeSnapshotRestoreSize(snapshot))
+		unusedSnapshots = append(unusedSnapshots, ResourceInfo{Name: snapshot.GetName(), Reason: reason})
+	}
+
+	return unusedSnapshots, nil
+}
+
+// Contents with a Delete policy are removed by the snapshot controller along with their VolumeSnapshot, only Retain leaves them behind
+func processVolumeSnapshotContents(dynamicClient dynamic.Interface, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	contents, err := dynamicClient.Resource(volumeSnapshotContentGVR).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots, err := dynamicClient.Resource(volumeSnapshotGVR).Namespace(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	snapshotUIDs := make(map[string]string, len(snapshots.Items))
+	for _, snapshot := range snapshots.Items {
+		snapshotUIDs[snapshot.GetNamespace()+"/"+snapshot.GetName()] = string(snapshot.GetUID())
+	}
+
+	var unusedContents []ResourceInfo
+
+	for _, content := range contents.Items {
+		if pass, _ := filter.SetObject(&content).Run(filterOpts); pass {
+			continue
+		}
+
+		if content.GetLabels()["kor/used"] == "false" {
+			unusedContents = append(unusedContents, ResourceInfo{Name: content.GetName(), Reason: "Marked with unused label"})
+			continue
+		}
+
+		if policy, _, _ := unstructured.NestedString(content.Object, "spec", "deletionPolicy"); policy != "Retain" {
+			continue
+		}
+
+		namespace, _, _ := unstructured.NestedString(content.Object, "spec", "volumeSnapshotRef", "namespace")
+		name, _, _ := unstructured.NestedString(content.Object, "spec", "volumeSnapshotRef", "name")
+		uid, _, _ := unstructured.NestedString(content.Object, "spec", "volumeSnapshotRef", "uid")
+
+		// A VolumeSnapshot recreated with the same name does not bind the content again
+		if snapshotUID, exists := snapshotUIDs[namespace+"/"+name]; exists && (uid == "" || uid == snapshotUID) {
+			continue
+		}
+
+		restoreSize := volumeSnapshotContentRestoreSize(content)
+		reason := fmt.Sprintf("VolumeSnapshotContent is not bound to any VolumeSnapshot, Retain deletion policy keeps the snapshot, restore size %s", restoreSize)
+		if name != "" {
+			reason = fmt.Sprintf("VolumeSnapshotContent is bound to VolumeSnapshot %s/%s which no longer exists, Retain deletion policy keeps the snapshot, restore size %s", namespace, name, restoreSize)
+		}
+		unusedContents = append(unusedContents, ResourceInfo{Name: content.GetName(), Reason: reason})
+	}
+
+	return unusedContents, nil
+}
+
+// Find the VolumeSnapshotClasses referenced by VolumeSnapshots and VolumeSnapshotContents, and whether some VolumeSnapshot relies on the default class
+func retrieveUsedVolumeSnapshotClasses(dynamicClient dynamic.Interface) ([]string, bool, error) {
+	var usedClasses []string
+	usesDefault := false
+
+	snapshots, err := dynamicClient.Resource(volumeSnapshotGVR).Namespace(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, false, err
+	}
+	for _, snapshot := range snapshots.Items {
+		if className, _, _ := unstructured.NestedString(snapshot.Object, "spec", "volumeSnapshotClassName"); className != "" {
+			usedClasses = append(usedClasses, className)
+		} else {
+			usesDefault = true
+		}
+	}
+
+	contents, err := dynamicClient.Resource(volumeSnapshotContentGVR).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, false, err
+	}
+	for _, content := range contents.Items {
+		if className, _, _ := unstructured.NestedString(content.Object, "spec", "volumeSnapshotClassName"); className != "" {
+			usedClasses = append(usedClasses, className)
+		}
+	}
+
+	return RemoveDuplicatesAndSort(usedClasses), usesDefault, nil
+}
+
+func processVolumeSnapshotClasses(dynamicClient dynamic.Interface, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	classes, err := dynamicClient.Resource(volumeSnapshotClassGVR).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+	if err != nil {
+		return nil, err
+	}
+
+	usedClasses, usesDefault, err := retrieveUsedVolumeSnapshotClasses(dynamicClient)
+	if err != nil {
+		return nil, err
+	}
+
+	var unusedClasses []ResourceInfo
+	classNames := make([]string, 0, len(classes.Items))
+	defaultClasses := make(map[string]bool)
+
+	for _, class := range classes.Items {
+		if pass, _ := filter.SetObject(&class).Run(filterOpts); pass {
+			continue
+		}
+
+		if class.GetLabels()["kor/used"] == "false" {
+			unusedClasses = append(unusedClasses, ResourceInfo{Name: class.GetName(), Reason: "Marked with unused label"})
+			continue
+		}
+
+		if class.GetAnnotations()[defaultVolumeSnapshotClassAnnotation] == "true" {
+			if usesDefault {
+				continue
+			}
+			defaultClasses[class.GetName()] = true
+		}
+
+		classNames = append(classNames, class.GetName())
+	}
+
+	diff := CalculateResourceDifference(usedClasses, classNames)
+	for _, name := range diff {
+		reason := "VolumeSnapshotClass is not used by any VolumeSnapshot or VolumeSnapshotContent"
+		if defaultClasses[name] {
+			reason = "VolumeSnapshotClass is the default class but no VolumeSnapshot uses it"
+		}
+		unusedClasses = append(unusedClasses, ResourceInfo{Name: name, Reason: reason})
+	}
+	return unusedClasses, nil
+}
+
+func GetUnusedVolumeSnapshots(filterOpts *filters.Options, clientset kubernetes.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts) (string, error) {
+	resources := make(map[string]map[string][]ResourceInfo)
+
+	served, err := isVolumeSnapshotServed(clientset)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to discover %s: %v\n", volumeSnapshotGVR.GroupVersion(), err)
+	} else if !served {
+		fmt.Fprintf(os.Stderr, "%s is not served, skipping VolumeSnapshots\n", volumeSnapshotGVR.GroupVersion())
+	}
+
+	if served {
+		for _, namespace := range filterOpts.Namespaces(clientset) {
+			diff, err := processNamespaceVolumeSnapshots(clientset, dynamicClient, namespace, filterOpts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
+				continue
+			}
+			if opts.DeleteFlag {
+				if diff, err = DeleteDynamicResource(diff, dynamicClient, namespace, volumeSnapshotGVR, "VolumeSnapshot", opts.NoInteractive); err != nil {
+					fmt.Fprintf(os.Stderr, "Failed to delete VolumeSnapshot %s in namespace %s: %v\n", diff, namespace, err)
+				}
+			}
+			switch opts.GroupBy {
+			case "namespace":
+				if diff != nil {
+					resources[namespace] = make(map[string][]ResourceInfo)
+					resources[namespace]["VolumeSnapshot"] = diff
+				}
+			case "resource":
+				if diff != nil {
+					appendResources(resources, "VolumeSnapshot", namespace, diff)
+				}
+			}
+		}
+
+		clusterDiffs := make(map[string][]ResourceInfo)
+		for _, detector := range []struct {
+			kind    string
+			gvr     schema.GroupVersionResource
+			process func(dynamic.Interface, *filters.Options) ([]ResourceInfo, error)
+		}{
+			{"VolumeSnapshotContent", volumeSnapshotContentGVR, processVolumeSnapshotContents},
+			{"VolumeSnapshotClass", volumeSnapshotClassGVR, processVolumeSnapshotClasses},
+		} {
+			diff, err := detector.process(dynamicClient, filterOpts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to process %s: %v\n", detector.gvr.Resource, err)
+				continue
+			}
+			if opts.DeleteFlag {
+				if diff, err = DeleteDynamicResource(diff, dynamicClient, "", detector.gvr, detector.kind, opts.NoInteractive); err != nil {
+					fmt.Fprintf(os.Stderr, "Failed to delete %s %s: %v\n", detector.kind, diff, err)
+				}
+			}
+			if diff != nil {
+				clusterDiffs[detector.kind] = diff
+			}
+		}
+		for kind, diff := range clusterDiffs {
+			switch opts.GroupBy {
+			case "namespace":
+				if resources[""] == nil {
+					resources[""] = make(map[string][]ResourceInfo)
+				}
+				resources[""][kind] = diff
+			case "resource":
+				appendResources(resources, kind, "", diff)
+			}
+		}
+	}
+
+	var outputBuffer bytes.Buffer
+	var jsonResponse []byte
+	switch outputFormat {
+	case "table":
+		outputBuffer = FormatOutput(resources, opts)
+	case "json", "yaml":
+		var err error
+		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
+			return "", err
+		}
+	}
+
+	unusedVolumeSnapshots, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
+	if err != nil {
+		fmt.Printf("err: %v\n", err)
+	}
+
+	return unusedVolumeSnapshots, nil
+}
@@ -0,0 +1,242 @@
+package kor
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/dynamic/fake"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func createTestVolumeSnapshot(name, pvcName, className, restoreSize string, age time.Duration) *unstructured.Unstructured {
+	snapshot := CreateTestUnstructered("VolumeSnapshot", "snapshot.storage.k8s.io/v1", testNamespace, name)
+	snapshot.SetUID(types.UID(name + "-uid"))
+	snapshot.SetCreationTimestamp(v1.NewTime(time.Now().Add(-age)))
+	if pvcName != "" {
+		_ = unstructured.SetNestedField(snapshot.Object, pvcName, "spec", "source", "persistentVolumeClaimName")
+	} else {
+		_ = unstructured.SetNestedField(snapshot.Object, name+"-content", "spec", "source", "volumeSnapshotContentName")
+	}
+	if className != "" {
+		_ = unstructured.SetNestedField(snapshot.Object, className, "spec", "volumeSnapshotClassName")
+	}
+	if restoreSize != "" {
+		_ = unstructured.SetNestedField(snapshot.Object, restoreSize, "status", "restoreSize")
+	}
+	return snapshot
+}
+
+func createTestVolumeSnapshotContent(name, deletionPolicy, snapshotName, snapshotUID, className string, restoreSize int64) *unstructured.Unstructured {
+	content := CreateTestUnstructered("VolumeSnapshotContent", "snapshot.storage.k8s.io/v1", "", name)
+	_ = unstructured.SetNestedField(content.Object, deletionPolicy, "spec", "deletionPolicy")
+	_ = unstructured.SetNestedField(content.Object, className, "spec", "volumeSnapshotClassName")
+	if snapshotName != "" {
+		_ = unstructured.SetNestedStringMap(content.Object, map[string]string{"namespace": testNamespace, "name": snapshotName, "uid": snapshotUID}, "spec", "volumeSnapshotRef")
+	}
+	_ = unstructured.SetNestedField(content.Object, restoreSize, "status", "restoreSize")
+	return content
+}
+
+func createTestVolumeSnapshotClass(name string, isDefault bool, labels map[string]string) *unstructured.Unstructured {
+	class := CreateTestUnstructered("VolumeSnapshotClass", "snapshot.storage.k8s.io/v1", "", name)
+	class.SetLabels(labels)
+	if isDefault {
+		class.SetAnnotations(map[string]string{defaultVolumeSnapshotClassAnnotation: "true"})
+	}
+	return class
+}
+
+func createTestVolumeSnapshots(t *testing.T) (*fakeclientset.Clientset, *fake.FakeDynamicClient) {
+	clientset := fakeclientset.NewSimpleClientset()
+
+	_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), &corev1.Namespace{
+		ObjectMeta: v1.ObjectMeta{Name: testNamespace},
+	}, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: v1.ObjectMeta{Namespace: testNamespace, Name: "data"}}
+	_, err = clientset.CoreV1().PersistentVolumeClaims(testNamespace).Create(context.TODO(), pvc, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake %s: %v", "PersistentVolumeClaim", err)
+	}
+
+	dynamicClient := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			volumeSnapshotGVR:        "VolumeSnapshotList",
+			volumeSnapshotContentGVR: "VolumeSnapshotContentList",
+			volumeSnapshotClassGVR:   "VolumeSnapshotClassList",
+		},
+		createTestVolumeSnapshot("live", "data", "csi-snapclass", "10Gi", 30*24*time.Hour),
+		createTestVolumeSnapshot("orphaned", "deleted", "csi-snapclass", "5Gi", 30*24*time.Hour),
+		createTestVolumeSnapshot("recent", "deleted", "", "1Gi", time.Hour),
+		createTestVolumeSnapshot("imported", "", "csi-snapclass", "", 30*24*time.Hour),
+		createTestVolumeSnapshotContent("content-bound", "Retain", "live", "live-uid", "csi-snapclass", 10*1024*1024*1024),
+		createTestVolumeSnapshotContent("content-deleted", "Delete", "gone", "gone-uid", "csi-snapclass", 1024),
+		createTestVolumeSnapshotContent("content-retained", "Retain", "gone", "gone-uid", "legacy-snapclass", 2*1024*1024*1024),
+		createTestVolumeSnapshotContent("content-recreated", "Retain", "orphaned", "old-uid", "csi-snapclass", 1024*1024),
+		createTestVolumeSnapshotClass("csi-snapclass", false, AppLabels),
+		createTestVolumeSnapshotClass("legacy-snapclass", false, AppLabels),
+		createTestVolumeSnapshotClass("default-snapclass", true, AppLabels),
+		createTestVolumeSnapshotClass("unused-snapclass", false, AppLabels),
+		createTestVolumeSnapshotClass("marked-snapclass", false, UnusedLabels),
+	)
+
+	return clientset, dynamicClient
+}
+
+func TestProcessNamespaceVolumeSnapshots(t *testing.T) {
+	clientset, dynamicClient := createTestVolumeSnapshots(t)
+
+	unusedSnapshots, err := processNamespaceVolumeSnapshots(clientset, dynamicClient, testNamespace, &filters.Options{VolumeSnapshotOlderThan: 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []ResourceInfo{
+		{Name: "orphaned", Reason: "VolumeSnapshot source PVC deleted no longer exists, restore size 5Gi"},
+	}
+	if !reflect.DeepEqual(unusedSnapshots, expected) {
+		t.Errorf("Expected %v, got %v", expected, unusedSnapshots)
+	}
+}
+
+func TestProcessVolumeSnapshotContents(t *testing.T) {
+	_, dynamicClient := createTestVolumeSnapshots(t)
+
+	unusedContents, err := processVolumeSnapshotContents(dynamicClient, &filters.Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []ResourceInfo{
+		{Name: "content-recreated", Reason: "VolumeSnapshotContent is bound to VolumeSnapshot test-namespace/orphaned which no longer exists, Retain deletion policy keeps the snapshot, restore size 1Mi"},
+		{Name: "content-retained", Reason: "VolumeSnapshotContent is bound to VolumeSnapshot test-namespace/gone which no longer exists, Retain deletion policy keeps the snapshot, restore size 2Gi"},
+	}
+	if !reflect.DeepEqual(unusedContents, expected) {
+		t.Errorf("Expected %v, got %v", expected, unusedContents)
+	}
+}
+
+func TestProcessVolumeSnapshotClasses(t *testing.T) {
+	_, dynamicClient := createTestVolumeSnapshots(t)
+
+	unusedClasses, err := processVolumeSnapshotClasses(dynamicClient, &filters.Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The recent snapshot has no class and is handled by the default class
+	expected := []ResourceInfo{
+		{Name: "marked-snapclass", Reason: "Marked with unused label"},
+		{Name: "unused-snapclass", Reason: "VolumeSnapshotClass is not used by any VolumeSnapshot or VolumeSnapshotContent"},
+	}
+	if !reflect.DeepEqual(unusedClasses, expected) {
+		t.Errorf("Expected %v, got %v", expected, unusedClasses)
+	}
+}
+
+func TestGetUnusedVolumeSnapshotsStructured(t *testing.T) {
+	clientset, dynamicClient := createTestVolumeSnapshots(t)
+
+	opts := common.Opts{
+		WebhookURL:    "",
+		Channel:       "",
+		Token:         "",
+		DeleteFlag:    false,
+		NoInteractive: true,
+		GroupBy:       "namespace",
+	}
+	filterOpts := &filters.Options{VolumeSnapshotOlderThan: 24 * time.Hour, IncludeNamespaces: []string{testNamespace}}
+
+	output, err := GetUnusedVolumeSnapshots(filterOpts, clientset, dynamicClient, "json", opts)
+	if err != nil {
+		t.Fatalf("Error calling GetUnusedVolumeSnapshots: %v", err)
+	}
+	if output != "{}" {
+		t.Errorf("Expected no findings when %s is not served, got %s", volumeSnapshotGVR.GroupVersion(), output)
+	}
+
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*v1.APIResourceList{
+		{GroupVersion: volumeSnapshotGVR.GroupVersion().String()},
+	}
+
+	output, err = GetUnusedVolumeSnapshots(filterOpts, clientset, dynamicClient, "json", opts)
+	if err != nil {
+		t.Fatalf("Error calling GetUnusedVolumeSnapshots: %v", err)
+	}
+
+	expectedOutput := map[string]map[string][]string{
+		testNamespace: {
+			"VolumeSnapshot": {"orphaned"},
+		},
+		"": {
+			"VolumeSnapshotContent": {"content-recreated", "content-retained"},
+			"VolumeSnapshotClass":   {"marked-snapclass", "unused-snapclass"},
+		},
+	}
+
+	var actualOutput map[string]map[string][]string
+	if err := json.Unmarshal([]byte(output), &actualOutput); err != nil {
+		t.Fatalf("Error unmarshaling actual output: %v", err)
+	}
+
+	if !reflect.DeepEqual(expectedOutput, actualOutput) {
+		t.Errorf("Expected output does not match actual output: %v", actualOutput)
+	}
+}
+
+func TestGetUnusedMultiVolumeSnapshots(t *testing.T) {
+	clientset, dynamicClient := createTestVolumeSnapshots(t)
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*v1.APIResourceList{
+		{GroupVersion: volumeSnapshotGVR.GroupVersion().String()},
+	}
+
+	opts := common.Opts{
+		WebhookURL:    "",
+		Channel:       "",
+		Token:         "",
+		DeleteFlag:    false,
+		NoInteractive: true,
+		GroupBy:       "namespace",
+	}
+	filterOpts := &filters.Options{VolumeSnapshotOlderThan: 24 * time.Hour, IncludeNamespaces: []string{testNamespace}}
+
+	output, err := GetUnusedMulti("pv,volumesnapshot", filterOpts, clientset, nil, dynamicClient, "json", opts)
+	if err != nil {
+		t.Fatalf("Error calling GetUnusedMulti: %v", err)
+	}
+
+	expectedOutput := map[string]map[string][]string{
+		testNamespace: {
+			"VolumeSnapshot": {"orphaned"},
+		},
+		"": {
+			"VolumeSnapshotContent": {"content-recreated", "content-retained"},
+			"VolumeSnapshotClass":   {"marked-snapclass", "unused-snapclass"},
+		},
+	}
+
+	var actualOutput map[string]map[string][]string
+	if err := json.Unmarshal([]byte(output), &actualOutput); err != nil {
+		t.Fatalf("Error unmarshaling actual output: %v", err)
+	}
+
+	if !reflect.DeepEqual(expectedOutput, actualOutput) {
+		t.Errorf("Expected output does not match actual output: %v", actualOutput)
+	}
+}
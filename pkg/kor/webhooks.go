@@ -0,0 +1,308 @@
+package kor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+var apiServiceGVR = schema.GroupVersionResource{Group: "apiregistration.k8s.io", Version: "v1", Resource: "apiservices"}
+
+// admissionWebhook holds the fields shared by validating and mutating webhook entries
+type admissionWebhook struct {
+	name              string
+	service           *admissionregistrationv1.ServiceReference
+	failurePolicy     *admissionregistrationv1.FailurePolicyType
+	namespaceSelector *metav1.LabelSelector
+	objectSelector    *metav1.LabelSelector
+	rules             []admissionregistrationv1.RuleWithOperations
+}
+
+type webhookConfiguration struct {
+	name     string
+	webhooks []admissionWebhook
+}
+
+// serviceBackendChecker looks up the Services called by webhooks and APIServices, caching the result per Service
+type serviceBackendChecker struct {
+	clientset kubernetes.Interface
+	problems  map[string]string
+}
+
+// Describe why a Service cannot serve requests, or return an empty string when it can
+func (c *serviceBackendChecker) problem(namespace, name string) (string, error) {
+	key := namespace + "/" + name
+	if problem, cached := c.problems[key]; cached {
+		return problem, nil
+	}
+
+	problem, err := c.lookup(namespace, name)
+	if err != nil {
+		return "", err
+	}
+	c.problems[key] = problem
+	return problem, nil
+}
+
+func (c *serviceBackendChecker) lookup(namespace, name string) (string, error) {
+	service, err := c.clientset.CoreV1().Services(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return "which does not exist", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	// ExternalName Services resolve through DNS and have no endpoints
+	if service.Spec.Type == corev1.ServiceTypeExternalName {
+		return "", nil
+	}
+
+	endpoints, err := c.clientset.CoreV1().Endpoints(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return "which has no ready endpoints", nil
+	} else if err != nil {
+		return "", err
+	}
+	for _, subset := range endpoints.Subsets {
+		if len(subset.Addresses) > 0 {
+			return "", nil
+		}
+	}
+	return "which has no ready endpoints", nil
+}
+
+// A nil selector matches everything, unlike for PDBs
+func selectsEverything(selector *metav1.LabelSelector) bool {
+	return selector == nil || isEmptySelector(selector)
+}
+
+// List the resources matched by webhook rules, and whether a wildcard makes them match every resource
+func webhookRuleResources(rules []admissionregistrationv1.RuleWithOperations) ([]string, bool) {
+	var resources []string
+	for _, rule := range rules {
+		for _, resource := range rule.Resources {
+			if resource == "*" || resource == "*/*" {
+				return nil, true
+			}
+			for _, group := range rule.APIGroups {
+				if group == "" {
+					resources = append(resources, resource)
+				} else {
+					resources = append(resources, resource+"."+group)
+				}
+			}
+		}
+	}
+	return RemoveDuplicatesAndSort(resources), false
+}
+
+// A webhook failing closed on every namespace and resource blocks writes cluster-wide, one failing open only adds latency
+func webhookSeverity(webhook admissionWebhook) (int, string) {
+	if webhook.failurePolicy != nil && *webhook.failurePolicy == admissionregistrationv1.Ignore {
+		return severityMedium, "failurePolicy Ignore"
+	}
+	if !selectsEverything(webhook.namespaceSelector) || !selectsEverything(webhook.objectSelector) {
+		return severityHigh, "failurePolicy Fail, selected namespaces or objects"
+	}
+	resources, allResources := webhookRuleResources(webhook.rules)
+	if allResources {
+		return severityCritical, "failurePolicy Fail, all namespaces and objects"
+	}
+	if len(resources) == 0 {
+		return severityMedium, "failurePolicy Fail, no rules"
+	}
+	return severityHigh, fmt.Sprintf("failurePolicy Fail, all namespaces, resources %s", strings.Join(resources, ", "))
+}
+
+func processWebhookConfigurations(checker *serviceBackendChecker, configurations []webhookConfiguration) ([]ResourceInfo, error) {
+	var findings []severityFinding
+	for _, configuration := range configurations {
+		for _, webhook := range configuration.webhooks {
+			// Webhooks called through a URL are outside the cluster
+			if webhook.service == nil {
+				continue
+			}
+			problem, err := checker.problem(webhook.service.Namespace, webhook.service.Name)
+			if err != nil {
+				return nil, err
+			}
+			if problem == "" {
+				continue
+			}
+			severity, scope := webhookSeverity(webhook)
+			reason := fmt.Sprintf("[%s] Webhook %s calls Service %s/%s %s (%s)", severityNames[severity], webhook.name, webhook.service.Namespace, webhook.service.Name, problem, scope)
+			findings = append(findings, severityFinding{severity, ResourceInfo{Name: configuration.name, Reason: reason}})
+		}
+	}
+	return sortFindingsBySeverity(findings), nil
+}
+
+func retrieveValidatingWebhookConfigurations(clientset kubernetes.Interface, filterOpts *filters.Options) ([]webhookConfiguration, error) {
+	configurations, err := clientset.AdmissionregistrationV1().ValidatingWebhookConfigurations().List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []webhookConfiguration
+	for _, configuration := range configurations.Items {
+		if pass, _ := filter.SetObject(&configuration).Run(filterOpts); pass {
+			continue
+		}
+
+		webhooks := make([]admissionWebhook, 0, len(configuration.Webhooks))
+		for _, webhook := range configuration.Webhooks {
+			webhooks = append(webhooks, admissionWebhook{webhook.Name, webhook.ClientConfig.Service, webhook.FailurePolicy, webhook.NamespaceSelector, webhook.ObjectSelector, webhook.Rules})
+		}
+		result = append(result, webhookConfiguration{configuration.Name, webhooks})
+	}
+	return result, nil
+}
+
+func retrieveMutatingWebhookConfigurations(clientset kubernetes.Interface, filterOpts *filters.Options) ([]webhookConfiguration, error) {
+	configurations, err := clientset.AdmissionregistrationV1().MutatingWebhookConfigurations().List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []webhookConfiguration
+	for _, configuration := range configurations.Items {
+		if pass, _ := filter.SetObject(&configuration).Run(filterOpts); pass {
+			continue
+		}
+
+		webhooks := make([]admissionWebhook, 0, len(configuration.Webhooks))
+		for _, webhook := range configuration.Webhooks {
+			webhooks = append(webhooks, admissionWebhook{webhook.Name, webhook.ClientConfig.Service, webhook.FailurePolicy, webhook.NamespaceSelector, webhook.ObjectSelector, webhook.Rules})
+		}
+		result = append(result, webhookConfiguration{configuration.Name, webhooks})
+	}
+	return result, nil
+}
+
+// An aggregated API without a backend breaks discovery and blocks deletion of namespaces holding its resources
+func processAPIServices(dynamicClient dynamic.Interface, checker *serviceBackendChecker, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	apiServices, err := dynamicClient.Resource(apiServiceGVR).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []severityFinding
+	for _, apiService := range apiServices.Items {
+		if pass, _ := filter.SetObject(&apiService).Run(filterOpts); pass {
+			continue
+		}
+
+		// APIServices without a Service are served by the kube-apiserver itself
+		namespace, _, _ := unstructured.NestedString(apiService.Object, "spec", "service", "namespace")
+		name, found, _ := unstructured.NestedString(apiService.Object, "spec", "service", "name")
+		if !found || name == "" {
+			continue
+		}
+		problem, err := checker.problem(namespace, name)
+		if err != nil {
+			return nil, err
+		}
+		if problem == "" {
+			continue
+		}
+		reason := fmt.Sprintf("[%s] APIService is served by Service %s/%s %s", severityNames[severityHigh], namespace, name, problem)
+		findings = append(findings, severityFinding{severityHigh, ResourceInfo{Name: apiService.GetName(), Reason: reason}})
+	}
+	return sortFindingsBySeverity(findings), nil
+}
+
+func retrieveBrokenWebhooks(clientset kubernetes.Interface, dynamicClient dynamic.Interface, filterOpts *filters.Options) (map[string][]ResourceInfo, error) {
+	checker := &serviceBackendChecker{clientset: clientset, problems: make(map[string]string)}
+	brokenWebhooks := make(map[string][]ResourceInfo)
+
+	validatingConfigurations, err := retrieveValidatingWebhookConfigurations(clientset, filterOpts)
+	if err != nil {
+		return nil, err
+	}
+	validating, err := processWebhookConfigurations(checker, validatingConfigurations)
+	if err != nil {
+		return nil, err
+	}
+	if len(validating) > 0 {
+		brokenWebhooks["ValidatingWebhookConfiguration"] = validating
+	}
+
+	mutatingConfigurations, err := retrieveMutatingWebhookConfigurations(clientset, filterOpts)
+	if err != nil {
+		return nil, err
+	}
+	mutating, err := processWebhookConfigurations(checker, mutatingConfigurations)
+	if err != nil {
+		return nil, err
+	}
+	if len(mutating) > 0 {
+		brokenWebhooks["MutatingWebhookConfiguration"] = mutating
+	}
+
+	apiServices, err := processAPIServices(dynamicClient, checker, filterOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to process APIServices: %v\n", err)
+	} else if len(apiServices) > 0 {
+		brokenWebhooks["APIService"] = apiServices
+	}
+
+	return brokenWebhooks, nil
+}
+
+func GetBrokenWebhooks(filterOpts *filters.Options, clientset kubernetes.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts) (string, error) {
+	if opts.DeleteFlag {
+		fmt.Fprintf(os.Stderr, "Deleting webhook findings is not supported, ignoring --delete flag\n")
+	}
+
+	brokenWebhooks, err := retrieveBrokenWebhooks(clientset, dynamicClient, filterOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to process webhooks: %v\n", err)
+	}
+
+	resources := make(map[string]map[string][]ResourceInfo)
+	for kind, findings := range brokenWebhooks {
+		switch opts.GroupBy {
+		case "namespace":
+			if resources[""] == nil {
+				resources[""] = make(map[string][]ResourceInfo)
+			}
+			resources[""][kind] = findings
+		case "resource":
+			appendResources(resources, kind, "", findings)
+		}
+	}
+
+	var outputBuffer bytes.Buffer
+	var jsonResponse []byte
+	switch outputFormat {
+	case "table":
+		outputBuffer = FormatOutput(resources, opts)
+	case "json", "yaml":
+		var err error
+		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
+			return "", err
+		}
+	}
+
+	brokenWebhookReport, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
+	if err != nil {
+		fmt.Printf("err: %v\n", err)
+	}
+
+	return brokenWebhookReport, nil
+}
@@ -0,0 +1,118 @@
+package kor
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func createTestAPIService(name, serviceNamespace, serviceName string) *unstructured.Unstructured {
+	apiService := CreateTestUnstructered("APIService", "apiregistration.k8s.io/v1", "", name)
+	if serviceName != "" {
+		_ = unstructured.SetNestedField(apiService.Object, map[string]interface{}{"namespace": serviceNamespace, "name": serviceName}, "spec", "service")
+	}
+	return apiService
+}
+
+func TestRetrieveBrokenWebhooks(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+
+	for _, name := range []string{"running", "scaled-down"} {
+		_, err := clientset.CoreV1().Services(testNamespace).Create(context.TODO(), CreateTestService(testNamespace, name), v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake %s: %v", "Service", err)
+		}
+	}
+
+	running := CreateTestEndpoint(testNamespace, "running", 0, AppLabels)
+	running.Subsets = []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}}}
+	scaledDown := CreateTestEndpoint(testNamespace, "scaled-down", 0, AppLabels)
+	scaledDown.Subsets = []corev1.EndpointSubset{{NotReadyAddresses: []corev1.EndpointAddress{{IP: "10.0.0.2"}}}}
+	for _, endpoints := range []*corev1.Endpoints{running, scaledDown} {
+		_, err := clientset.CoreV1().Endpoints(testNamespace).Create(context.TODO(), endpoints, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake %s: %v", "Endpoints", err)
+		}
+	}
+
+	service := func(name string) admissionregistrationv1.WebhookClientConfig {
+		return admissionregistrationv1.WebhookClientConfig{Service: &admissionregistrationv1.ServiceReference{Namespace: testNamespace, Name: name}}
+	}
+	fail := admissionregistrationv1.Fail
+	ignore := admissionregistrationv1.Ignore
+	url := "https://webhook.example.com"
+	rules := func(group, resource string) []admissionregistrationv1.RuleWithOperations {
+		return []admissionregistrationv1.RuleWithOperations{{
+			Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.OperationAll},
+			Rule:       admissionregistrationv1.Rule{APIGroups: []string{group}, APIVersions: []string{"*"}, Resources: []string{resource}},
+		}}
+	}
+
+	validating := &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: v1.ObjectMeta{Name: "policy-engine"},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{
+			{Name: "healthy.example.com", ClientConfig: service("running"), FailurePolicy: &fail},
+			{Name: "external.example.com", ClientConfig: admissionregistrationv1.WebhookClientConfig{URL: &url}, FailurePolicy: &fail},
+			{Name: "scoped.example.com", ClientConfig: service("scaled-down"), FailurePolicy: &fail,
+				NamespaceSelector: &v1.LabelSelector{MatchLabels: map[string]string{"policy": "enforced"}}},
+			{Name: "everything.example.com", ClientConfig: service("uninstalled"), Rules: rules("*", "*")},
+			{Name: "widgets.example.com", ClientConfig: service("uninstalled"), FailurePolicy: &fail,
+				NamespaceSelector: &v1.LabelSelector{}, Rules: rules("example.com", "widgets")},
+		},
+	}
+	_, err := clientset.AdmissionregistrationV1().ValidatingWebhookConfigurations().Create(context.TODO(), validating, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake %s: %v", "ValidatingWebhookConfiguration", err)
+	}
+
+	mutating := &admissionregistrationv1.MutatingWebhookConfiguration{
+		ObjectMeta: v1.ObjectMeta{Name: "sidecar-injector"},
+		Webhooks: []admissionregistrationv1.MutatingWebhook{
+			{Name: "inject.example.com", ClientConfig: service("uninstalled"), FailurePolicy: &ignore},
+		},
+	}
+	_, err = clientset.AdmissionregistrationV1().MutatingWebhookConfigurations().Create(context.TODO(), mutating, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake %s: %v", "MutatingWebhookConfiguration", err)
+	}
+
+	dynamicClient := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{apiServiceGVR: "APIServiceList"},
+		createTestAPIService("v1.apps", "", ""),
+		createTestAPIService("v1beta1.metrics.k8s.io", testNamespace, "running"),
+		createTestAPIService("v1alpha1.custom.example.com", testNamespace, "uninstalled"),
+	)
+
+	brokenWebhooks, err := retrieveBrokenWebhooks(clientset, dynamicClient, &filters.Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := map[string][]ResourceInfo{
+		"ValidatingWebhookConfiguration": {
+			{Name: "policy-engine", Reason: "[critical] Webhook everything.example.com calls Service test-namespace/uninstalled which does not exist (failurePolicy Fail, all namespaces and objects)"},
+			{Name: "policy-engine", Reason: "[high] Webhook scoped.example.com calls Service test-namespace/scaled-down which has no ready endpoints (failurePolicy Fail, selected namespaces or objects)"},
+			{Name: "policy-engine", Reason: "[high] Webhook widgets.example.com calls Service test-namespace/uninstalled which does not exist (failurePolicy Fail, all namespaces, resources widgets.example.com)"},
+		},
+		"MutatingWebhookConfiguration": {
+			{Name: "sidecar-injector", Reason: "[medium] Webhook inject.example.com calls Service test-namespace/uninstalled which does not exist (failurePolicy Ignore)"},
+		},
+		"APIService": {
+			{Name: "v1alpha1.custom.example.com", Reason: "[high] APIService is served by Service test-namespace/uninstalled which does not exist"},
+		},
+	}
+	if !reflect.DeepEqual(brokenWebhooks, expected) {
+		t.Errorf("Expected %v, got %v", expected, brokenWebhooks)
+	}
+}